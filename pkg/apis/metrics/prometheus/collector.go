@@ -0,0 +1,204 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prometheus translates the kubelet metrics package's Raw*Metrics types into a standard
+// Prometheus/OpenMetrics exposition, so ecosystem tools (kube-state-metrics, node-exporter
+// consumers, Grafana dashboards) get a first-class scrape target without needing to speak the
+// module's bespoke JSON schema.
+package prometheus
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"k8s.io/kubernetes/pkg/apis/metrics"
+)
+
+// MetricsSource is implemented by the existing raw metrics providers (e.g. the kubelet's
+// cAdvisor-backed provider and the dockertools ContainerStatsStreamer) to feed the Collector.
+type MetricsSource interface {
+	// LatestNodeMetrics returns the most recently collected RawNodeMetrics.
+	LatestNodeMetrics() (*metrics.RawNodeMetrics, error)
+	// LatestPodMetrics returns the most recently collected RawPodMetrics for every known pod.
+	LatestPodMetrics() ([]metrics.RawPodMetrics, error)
+}
+
+var (
+	containerCPUUsageSecondsTotal = prometheus.NewDesc(
+		"container_cpu_usage_seconds_total",
+		"Cumulative CPU time consumed by the container in core-seconds.",
+		[]string{"namespace", "pod", "container"}, nil)
+
+	containerMemoryWorkingSetBytes = prometheus.NewDesc(
+		"container_memory_working_set_bytes",
+		"Current working set memory usage of the container in bytes.",
+		[]string{"namespace", "pod", "container"}, nil)
+
+	containerNetworkReceiveBytesTotal = prometheus.NewDesc(
+		"container_network_receive_bytes_total",
+		"Cumulative bytes received on a network interface.",
+		[]string{"namespace", "pod", "interface"}, nil)
+
+	containerFsUsageBytes = prometheus.NewDesc(
+		"container_fs_usage_bytes",
+		"Bytes consumed by the container on a filesystem device.",
+		[]string{"namespace", "pod", "container", "device"}, nil)
+
+	nodeCPUSecondsTotal = prometheus.NewDesc(
+		"node_cpu_seconds_total",
+		"Cumulative CPU time consumed on the node in core-seconds.",
+		nil, nil)
+
+	nodeNetworkTCPConnections = prometheus.NewDesc(
+		"node_network_tcp_connections",
+		"Number of TCP connections in each state.",
+		[]string{"protocol", "state"}, nil)
+)
+
+// Collector is a prometheus.Collector that exposes the latest sample from a MetricsSource as
+// standard kubelet-style series.
+type Collector struct {
+	source MetricsSource
+}
+
+// NewCollector returns a Collector that reads from source.
+func NewCollector(source MetricsSource) *Collector {
+	return &Collector{
+		source: source,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- containerCPUUsageSecondsTotal
+	ch <- containerMemoryWorkingSetBytes
+	ch <- containerNetworkReceiveBytesTotal
+	ch <- containerFsUsageBytes
+	ch <- nodeCPUSecondsTotal
+	ch <- nodeNetworkTCPConnections
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	if node, err := c.source.LatestNodeMetrics(); err == nil && node != nil {
+		c.collectNode(ch, node)
+	}
+
+	pods, err := c.source.LatestPodMetrics()
+	if err != nil {
+		return
+	}
+	for i := range pods {
+		c.collectPod(ch, &pods[i])
+	}
+}
+
+func (c *Collector) collectNode(ch chan<- prometheus.Metric, node *metrics.RawNodeMetrics) {
+	latest := latestAggregateSample(node.Total)
+	if latest == nil || latest.CPU == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(nodeCPUSecondsTotal, prometheus.CounterValue,
+		latest.CPU.Cumulative.TotalCoreSeconds.AsApproximateFloat64())
+
+	if latest.Network != nil {
+		emitTCPStates(ch, "tcp", latest.Network.TCP)
+		emitTCPStates(ch, "tcp6", latest.Network.TCP6)
+	}
+}
+
+func (c *Collector) collectPod(ch chan<- prometheus.Metric, pod *metrics.RawPodMetrics) {
+	namespace, name := pod.PodRef.Namespace, pod.PodRef.Name
+
+	for _, container := range pod.Containers {
+		latest := latestContainerSample(container.Samples)
+		if latest == nil {
+			continue
+		}
+
+		if latest.CPU != nil {
+			ch <- prometheus.MustNewConstMetric(containerCPUUsageSecondsTotal, prometheus.CounterValue,
+				latest.CPU.Cumulative.TotalCoreSeconds.AsApproximateFloat64(),
+				namespace, name, container.Name)
+		}
+		if latest.Memory != nil {
+			ch <- prometheus.MustNewConstMetric(containerMemoryWorkingSetBytes, prometheus.GaugeValue,
+				latest.Memory.UsageBytes.AsApproximateFloat64(),
+				namespace, name, container.Name)
+		}
+		for _, fs := range latest.Filesystem {
+			ch <- prometheus.MustNewConstMetric(containerFsUsageBytes, prometheus.GaugeValue,
+				fs.Usage.AsApproximateFloat64(),
+				namespace, name, container.Name, fs.Device)
+		}
+	}
+
+	for i := len(pod.Samples) - 1; i >= 0; i-- {
+		sample := pod.Samples[i]
+		if sample.Network == nil {
+			continue
+		}
+		for _, iface := range sample.Network.Interfaces {
+			ch <- prometheus.MustNewConstMetric(containerNetworkReceiveBytesTotal, prometheus.CounterValue,
+				float64(iface.RxBytes), namespace, name, iface.Name)
+		}
+		break // pod.Samples is ordered oldest-to-newest; only the latest is reported.
+	}
+}
+
+func emitTCPStates(ch chan<- prometheus.Metric, protocol string, tcp metrics.TCPMetrics) {
+	states := map[string]int64{
+		"established": tcp.Established,
+		"synSent":     tcp.SynSent,
+		"synRecv":     tcp.SynRecv,
+		"finWait1":    tcp.FinWait1,
+		"finWait2":    tcp.FinWait2,
+		"timeWait":    tcp.TimeWait,
+		"close":       tcp.Close,
+		"closeWait":   tcp.CloseWait,
+		"lastAck":     tcp.LastAck,
+		"listen":      tcp.Listen,
+		"closing":     tcp.Closing,
+	}
+	for state, count := range states {
+		ch <- prometheus.MustNewConstMetric(nodeNetworkTCPConnections, prometheus.GaugeValue,
+			float64(count), protocol, state)
+	}
+}
+
+func latestAggregateSample(samples []metrics.AggregateSample) *metrics.AggregateSample {
+	if len(samples) == 0 {
+		return nil
+	}
+	return &samples[len(samples)-1]
+}
+
+func latestContainerSample(samples []metrics.ContainerSample) *metrics.ContainerSample {
+	if len(samples) == 0 {
+		return nil
+	}
+	return &samples[len(samples)-1]
+}
+
+// Handler returns an http.Handler that serves the Collector's series in Prometheus text
+// exposition format, mountable next to the existing JSON metrics endpoint.
+func Handler(source MetricsSource) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector(source))
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"k8s.io/kubernetes/pkg/apis/metrics"
+)
+
+var (
+	nodeCPUUsageNanoCores = prometheus.NewDesc(
+		"node_cpu_usage_nano_cores",
+		"Smoothed node CPU usage, in nanocores.",
+		nil, nil)
+
+	nodeMemoryWorkingSetBytes = prometheus.NewDesc(
+		"node_memory_working_set_bytes",
+		"Node working set memory, in bytes.",
+		nil, nil)
+
+	nodeFsAvailableBytes = prometheus.NewDesc(
+		"node_fs_available_bytes",
+		"Available bytes on the filesystem monitored by the node.",
+		nil, nil)
+
+	systemContainerCPUUsageNanoCores = prometheus.NewDesc(
+		"system_container_cpu_usage_nano_cores",
+		"Smoothed CPU usage of a node-level system container, in nanocores.",
+		[]string{"container"}, nil)
+
+	systemContainerMemoryWorkingSetBytes = prometheus.NewDesc(
+		"system_container_memory_working_set_bytes",
+		"Working set memory of a node-level system container, in bytes.",
+		[]string{"container"}, nil)
+
+	summaryContainerCPUUsageNanoCores = prometheus.NewDesc(
+		"container_cpu_usage_nano_cores",
+		"Smoothed CPU usage of a pod's container, in nanocores.",
+		[]string{"namespace", "pod", "container"}, nil)
+
+	summaryContainerMemoryWorkingSetBytes = prometheus.NewDesc(
+		"container_memory_working_set_bytes",
+		"Working set memory of a pod's container, in bytes.",
+		[]string{"namespace", "pod", "container"}, nil)
+
+	volumeFsAvailableBytes = prometheus.NewDesc(
+		"volume_fs_available_bytes",
+		"Available bytes on a pod volume's filesystem.",
+		[]string{"namespace", "pod", "volume"}, nil)
+)
+
+// SummaryCollector is a prometheus.Collector that re-emits a metrics.SummaryProvider's smoothed
+// Summary stats as standard Prometheus series, so a scraper can be pointed at the kubelet without
+// also standing up cAdvisor or the metrics-server adapter.
+type SummaryCollector struct {
+	provider metrics.SummaryProvider
+}
+
+// NewSummaryCollector returns a SummaryCollector backed by provider.
+func NewSummaryCollector(provider metrics.SummaryProvider) *SummaryCollector {
+	return &SummaryCollector{provider: provider}
+}
+
+// Describe implements prometheus.Collector.
+func (c *SummaryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodeCPUUsageNanoCores
+	ch <- nodeMemoryWorkingSetBytes
+	ch <- nodeFsAvailableBytes
+	ch <- systemContainerCPUUsageNanoCores
+	ch <- systemContainerMemoryWorkingSetBytes
+	ch <- summaryContainerCPUUsageNanoCores
+	ch <- summaryContainerMemoryWorkingSetBytes
+	ch <- volumeFsAvailableBytes
+}
+
+// Collect implements prometheus.Collector.
+func (c *SummaryCollector) Collect(ch chan<- prometheus.Metric) {
+	summary, err := c.provider.GetSummary(context.Background())
+	if err != nil || summary == nil {
+		return
+	}
+
+	c.collectNode(ch, &summary.Node)
+	for i := range summary.Pods {
+		c.collectPod(ch, &summary.Pods[i])
+	}
+}
+
+func (c *SummaryCollector) collectNode(ch chan<- prometheus.Metric, node *metrics.NodeStats) {
+	if node.CPU != nil && node.CPU.UsageNanoCores != nil {
+		ch <- prometheus.MustNewConstMetric(nodeCPUUsageNanoCores, prometheus.GaugeValue,
+			float64(*node.CPU.UsageNanoCores))
+	}
+	if node.Memory != nil && node.Memory.WorkingSetBytes != nil {
+		ch <- prometheus.MustNewConstMetric(nodeMemoryWorkingSetBytes, prometheus.GaugeValue,
+			float64(*node.Memory.WorkingSetBytes))
+	}
+	if node.Fs != nil && node.Fs.AvailableBytes != nil {
+		ch <- prometheus.MustNewConstMetric(nodeFsAvailableBytes, prometheus.GaugeValue,
+			float64(*node.Fs.AvailableBytes))
+	}
+
+	for _, container := range node.SystemContainers {
+		if container.CPU != nil && container.CPU.UsageNanoCores != nil {
+			ch <- prometheus.MustNewConstMetric(systemContainerCPUUsageNanoCores, prometheus.GaugeValue,
+				float64(*container.CPU.UsageNanoCores), container.Name)
+		}
+		if container.Memory != nil && container.Memory.WorkingSetBytes != nil {
+			ch <- prometheus.MustNewConstMetric(systemContainerMemoryWorkingSetBytes, prometheus.GaugeValue,
+				float64(*container.Memory.WorkingSetBytes), container.Name)
+		}
+	}
+}
+
+func (c *SummaryCollector) collectPod(ch chan<- prometheus.Metric, pod *metrics.PodStats) {
+	namespace, name := pod.PodRef.Namespace, pod.PodRef.Name
+
+	for _, container := range pod.Containers {
+		if container.CPU != nil && container.CPU.UsageNanoCores != nil {
+			ch <- prometheus.MustNewConstMetric(summaryContainerCPUUsageNanoCores, prometheus.GaugeValue,
+				float64(*container.CPU.UsageNanoCores), namespace, name, container.Name)
+		}
+		if container.Memory != nil && container.Memory.WorkingSetBytes != nil {
+			ch <- prometheus.MustNewConstMetric(summaryContainerMemoryWorkingSetBytes, prometheus.GaugeValue,
+				float64(*container.Memory.WorkingSetBytes), namespace, name, container.Name)
+		}
+	}
+
+	for _, volume := range pod.VolumeStats {
+		if volume.AvailableBytes != nil {
+			ch <- prometheus.MustNewConstMetric(volumeFsAvailableBytes, prometheus.GaugeValue,
+				float64(*volume.AvailableBytes), namespace, name, volume.Name)
+		}
+	}
+}
+
+// SummaryHandler returns an http.Handler that serves provider's Summary stats in Prometheus text
+// exposition format, mountable alongside the JSON /stats/summary endpoint.
+func SummaryHandler(provider metrics.SummaryProvider) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewSummaryCollector(provider))
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
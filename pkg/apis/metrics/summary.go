@@ -0,0 +1,305 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// SummaryProvider supplies a smoothed, single-point-in-time view of node and pod resource usage.
+// It lets external callers (metrics-server, HPA, out-of-tree kubelet providers) consume summary
+// stats without depending on the Raw*Metrics sample schema.
+type SummaryProvider interface {
+	// GetSummary returns the full node and pod summary, including filesystem and network stats.
+	GetSummary(ctx context.Context) (*Summary, error)
+	// GetCPUAndMemoryStats returns a summary populated with only the CPU and memory stats, which
+	// are cheap to collect and are all that's typically needed to drive autoscaling decisions.
+	GetCPUAndMemoryStats(ctx context.Context) (*Summary, error)
+}
+
+// AcceleratorStatsProvider supplies point-in-time stats for the hardware accelerators (GPUs and
+// similar) assigned to a single container. Device-plugin shims (NVIDIA, AMD, Intel, Habana, ...)
+// implement it and register an instance with RegisterAcceleratorStatsProvider, typically from the
+// same process that serves their plugin over the kubelet's plugin socket directory, so the
+// Summarizer can fold accelerator stats into ContainerStats without depending on any one vendor's
+// client library.
+type AcceleratorStatsProvider interface {
+	// GetAcceleratorStats returns the stats for every accelerator assigned to the named
+	// container, or nil if the provider has none to report.
+	GetAcceleratorStats(containerName string) ([]AcceleratorStats, error)
+}
+
+var (
+	acceleratorProvidersMu sync.Mutex
+	acceleratorProviders   []AcceleratorStatsProvider
+)
+
+// RegisterAcceleratorStatsProvider adds provider to the set the Summarizer consults when
+// populating ContainerStats.Accelerators. Safe to call concurrently with SummarizeContainer.
+func RegisterAcceleratorStatsProvider(provider AcceleratorStatsProvider) {
+	acceleratorProvidersMu.Lock()
+	defer acceleratorProvidersMu.Unlock()
+	acceleratorProviders = append(acceleratorProviders, provider)
+}
+
+// acceleratorStatsForContainer collects the accelerator stats every registered provider reports
+// for the named container. A provider error (e.g. its plugin socket is unreachable) is not fatal
+// to the summary; that provider simply contributes no stats for this round.
+func acceleratorStatsForContainer(name string) []AcceleratorStats {
+	acceleratorProvidersMu.Lock()
+	providers := append([]AcceleratorStatsProvider(nil), acceleratorProviders...)
+	acceleratorProvidersMu.Unlock()
+
+	var stats []AcceleratorStats
+	for _, provider := range providers {
+		provided, err := provider.GetAcceleratorStats(name)
+		if err != nil {
+			continue
+		}
+		stats = append(stats, provided...)
+	}
+	return stats
+}
+
+// Summarizer folds sample windows (as produced by the raw metrics collectors) into the smoothed
+// Summary view.
+type Summarizer struct{}
+
+// NewSummarizer returns a Summarizer.
+func NewSummarizer() *Summarizer {
+	return &Summarizer{}
+}
+
+// SummarizeNode folds a window of AggregateSample into a NodeStats for the named node.
+func (s *Summarizer) SummarizeNode(nodeName string, samples []AggregateSample) (*NodeStats, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to summarize for node %q", nodeName)
+	}
+
+	latest := samples[len(samples)-1]
+	stats := &NodeStats{
+		NodeName:  nodeName,
+		StartTime: samples[0].SampleTime,
+	}
+
+	if cpu, err := instantCPUFromAggregateSamples(samples); err != nil {
+		return nil, err
+	} else {
+		stats.CPU = cpu
+	}
+
+	stats.Memory = instantMemoryFromMemoryMetrics(latest.Memory, latest.SampleTime)
+	stats.Network = instantNetworkFromNetworkMetrics(latest.Network, latest.SampleTime)
+	stats.Fs = instantFsFromFilesystemMetrics(latest.Filesystem, latest.SampleTime)
+	stats.PSI = instantPSIFromPressureMetrics(latest.Pressure, latest.SampleTime)
+
+	return stats, nil
+}
+
+// SummarizeContainer folds a window of ContainerSample into a ContainerStats for the named
+// container.
+func (s *Summarizer) SummarizeContainer(name string, samples []ContainerSample) (*ContainerStats, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to summarize for container %q", name)
+	}
+
+	latest := samples[len(samples)-1]
+	stats := &ContainerStats{
+		Name:      name,
+		StartTime: samples[0].SampleTime,
+	}
+
+	cpu, err := instantCPUFromContainerSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+	stats.CPU = cpu
+	stats.Memory = instantMemoryFromMemoryMetrics(latest.Memory, latest.SampleTime)
+	stats.Rootfs = instantFsFromFilesystemMetrics(latest.Filesystem, latest.SampleTime)
+	stats.PSI = instantPSIFromPressureMetrics(latest.Pressure, latest.SampleTime)
+	stats.Accelerators = acceleratorStatsForContainer(name)
+
+	return stats, nil
+}
+
+// SummarizePod folds the Raw metrics of a pod's containers into a PodStats.
+func (s *Summarizer) SummarizePod(podRef NonLocalObjectReference, containers []RawContainerMetrics) (*PodStats, error) {
+	stats := &PodStats{PodRef: podRef}
+
+	for _, c := range containers {
+		cStats, err := s.SummarizeContainer(c.Name, c.Samples)
+		if err != nil {
+			// A container with no samples yet just contributes no stats; this is expected for
+			// freshly started containers.
+			continue
+		}
+		if stats.StartTime.IsZero() || cStats.StartTime.Before(&stats.StartTime) {
+			stats.StartTime = cStats.StartTime
+		}
+		stats.Containers = append(stats.Containers, *cStats)
+		stats.Accelerators = append(stats.Accelerators, cStats.Accelerators...)
+	}
+
+	return stats, nil
+}
+
+// instantCPUFromAggregateSamples computes instantaneous CPU usage as the delta of the cumulative
+// core-seconds counter across the oldest and newest samples in the window.
+func instantCPUFromAggregateSamples(samples []AggregateSample) (*CPUInstantStats, error) {
+	first, last := samples[0], samples[len(samples)-1]
+	if first.CPU == nil || last.CPU == nil {
+		return nil, nil
+	}
+	return instantCPUFromCumulative(first.SampleTime, first.CPU.Cumulative, last.SampleTime, last.CPU.Cumulative)
+}
+
+func instantCPUFromContainerSamples(samples []ContainerSample) (*CPUInstantStats, error) {
+	first, last := samples[0], samples[len(samples)-1]
+	if first.CPU == nil || last.CPU == nil {
+		return nil, nil
+	}
+	return instantCPUFromCumulative(first.SampleTime, first.CPU.Cumulative, last.SampleTime, last.CPU.Cumulative)
+}
+
+func instantCPUFromCumulative(firstTime unversioned.Time, first CPUCumulativeMetrics, lastTime unversioned.Time, last CPUCumulativeMetrics) (*CPUInstantStats, error) {
+	windowSeconds := lastTime.Sub(firstTime.Time).Seconds()
+
+	usageCoreNanoSeconds := uint64(last.TotalCoreSeconds.MilliValue()) * uint64(1e6)
+	stats := &CPUInstantStats{
+		Time:                 lastTime,
+		UsageCoreNanoSeconds: &usageCoreNanoSeconds,
+	}
+
+	if windowSeconds <= 0 {
+		// Can't compute a rate from a single sample or samples with a non-monotonic clock; report
+		// the cumulative counter only.
+		return stats, nil
+	}
+
+	deltaMilliCores := last.TotalCoreSeconds.MilliValue() - first.TotalCoreSeconds.MilliValue()
+	nanoCores := uint64(float64(deltaMilliCores) * 1e6 / windowSeconds)
+	stats.UsageNanoCores = &nanoCores
+
+	return stats, nil
+}
+
+func instantMemoryFromMemoryMetrics(mem *MemoryMetrics, t unversioned.Time) *MemoryInstantStats {
+	if mem == nil {
+		return nil
+	}
+	usage := uint64(mem.UsageBytes.Value())
+	total := uint64(mem.TotalBytes.Value())
+	stats := &MemoryInstantStats{
+		Time:            t,
+		UsageBytes:      &total,
+		WorkingSetBytes: &usage,
+	}
+	if mem.FailCount != nil {
+		pageFaults := uint64(*mem.FailCount)
+		stats.PageFaults = &pageFaults
+	}
+	return stats
+}
+
+func instantNetworkFromNetworkMetrics(net *NetworkMetrics, t unversioned.Time) *NetworkStats {
+	if net == nil {
+		return nil
+	}
+	stats := &NetworkStats{Time: t}
+	for _, iface := range net.Interfaces {
+		stats.Interfaces = append(stats.Interfaces, instantInterfaceFromInterfaceMetrics(iface))
+	}
+	if len(stats.Interfaces) > 0 {
+		stats.InterfaceStats = stats.Interfaces[0]
+	}
+	return stats
+}
+
+func instantInterfaceFromInterfaceMetrics(m InterfaceMetrics) InterfaceStats {
+	rx := uint64(m.RxBytes)
+	tx := uint64(m.TxBytes)
+	rxErrors := uint64(m.RxErrors)
+	txErrors := uint64(m.TxErrors)
+	rxDropped := uint64(m.RxDropped)
+	txDropped := uint64(m.TxDropped)
+	return InterfaceStats{
+		Name:      m.Name,
+		RxBytes:   &rx,
+		RxErrors:  &rxErrors,
+		RxDropped: &rxDropped,
+		TxBytes:   &tx,
+		TxErrors:  &txErrors,
+		TxDropped: &txDropped,
+	}
+}
+
+func instantPSIFromPressureMetrics(p *PressureMetrics, t unversioned.Time) *PSIStats {
+	if p == nil {
+		return nil
+	}
+	return &PSIStats{
+		Time:   t,
+		CPU:    instantPSIDataFromPSIMetrics(p.CPU),
+		Memory: instantPSIDataFromPSIMetrics(p.Memory),
+		IO:     instantPSIDataFromPSIMetrics(p.IO),
+	}
+}
+
+func instantPSIDataFromPSIMetrics(m *PSIMetrics) *PSIData {
+	if m == nil {
+		return nil
+	}
+	return &PSIData{
+		Some: instantPSIStatsDataFromPSILine(m.Some),
+		Full: instantPSIStatsDataFromPSILine(m.Full),
+	}
+}
+
+func instantPSIStatsDataFromPSILine(l PSILine) PSIStatsData {
+	total := uint64(l.Total.Value())
+	return PSIStatsData{
+		Avg10:  l.Avg10,
+		Avg60:  l.Avg60,
+		Avg300: l.Avg300,
+		Total:  &total,
+	}
+}
+
+func instantFsFromFilesystemMetrics(fses []FilesystemMetrics, t unversioned.Time) *FsStats {
+	if len(fses) == 0 {
+		return nil
+	}
+	// Report the first tracked device; callers that need per-device detail should consult the
+	// Raw metrics directly.
+	fs := fses[0]
+	used := uint64(fs.Usage.Value())
+	capacity := uint64(fs.Limit.Value())
+	available := uint64(0)
+	if capacity > used {
+		available = capacity - used
+	}
+	return &FsStats{
+		Time:           t,
+		UsedBytes:      &used,
+		CapacityBytes:  &capacity,
+		AvailableBytes: &available,
+	}
+}
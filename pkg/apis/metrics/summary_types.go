@@ -0,0 +1,271 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+// Summary is a top-level container for a single, smoothed point-in-time view of node and pod
+// resource usage, as consumed by the HPA, metrics-server, and out-of-tree kubelet providers.
+// Unlike the Raw*Metrics types it does not retain the underlying sample history.
+type Summary struct {
+	// Overall node stats.
+	Node NodeStats `json:"node"`
+	// Per-pod stats.
+	Pods []PodStats `json:"pods" patchStrategy:"merge" patchMergeKey:"podRef"`
+}
+
+// NodeStats holds node-level unprocessed sample stats.
+type NodeStats struct {
+	// Reference to the measured Node.
+	NodeName string `json:"nodeName"`
+	// The time at which data collection for this statistic was (re)started.
+	StartTime unversioned.Time `json:"startTime"`
+	// Stats of system daemons tracked as raw containers.
+	SystemContainers []ContainerStats `json:"systemContainers,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	// Overall node CPU stats.
+	CPU *CPUInstantStats `json:"cpu,omitempty"`
+	// Overall node memory stats.
+	Memory *MemoryInstantStats `json:"memory,omitempty"`
+	// Overall node network stats.
+	Network *NetworkStats `json:"network,omitempty"`
+	// Stats about the filesystem used by node fs monitoring.
+	Fs *FsStats `json:"fs,omitempty"`
+	// Stats about the rlimits of the node.
+	Rlimit *RlimitStats `json:"rlimit,omitempty"`
+	// Overall node Pressure Stall Information.
+	PSI *PSIStats `json:"psi,omitempty"`
+}
+
+// PodStats holds pod-level unprocessed sample stats.
+type PodStats struct {
+	// Reference to the measured Pod.
+	PodRef NonLocalObjectReference `json:"podRef"`
+	// The time at which data collection for this statistic was (re)started.
+	StartTime unversioned.Time `json:"startTime"`
+	// Stats of containers in the measured pod.
+	Containers []ContainerStats `json:"containers" patchStrategy:"merge" patchMergeKey:"name"`
+	// Stats pertaining to network resources.
+	Network *NetworkStats `json:"network,omitempty"`
+	// Stats pertaining to volumes backing the pod.
+	VolumeStats []VolumeStats `json:"volume,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	// EphemeralStorage aggregates the pod-level ephemeral storage usage.
+	EphemeralStorage *FsStats `json:"ephemeral-storage,omitempty"`
+	// Pressure Stall Information for the pod's cgroup, aggregated across its containers.
+	PSI *PSIStats `json:"psi,omitempty"`
+	// Accelerators aggregates the Accelerators stats of all of the pod's containers.
+	Accelerators []AcceleratorStats `json:"accelerators,omitempty" patchStrategy:"merge" patchMergeKey:"deviceID"`
+}
+
+// ContainerStats holds container-level unprocessed sample stats.
+type ContainerStats struct {
+	// Reference to the measured container.
+	Name string `json:"name"`
+	// The time at which data collection for this statistic was (re)started.
+	StartTime unversioned.Time `json:"startTime"`
+	// CPU stats, smoothed over the sampling window.
+	CPU *CPUInstantStats `json:"cpu,omitempty"`
+	// Memory stats.
+	Memory *MemoryInstantStats `json:"memory,omitempty"`
+	// Rootfs stats, backing the writable layer of the container.
+	Rootfs *FsStats `json:"rootfs,omitempty"`
+	// Logs stats, backing the container logs.
+	Logs *FsStats `json:"logs,omitempty"`
+	// User-defined metrics exposed by the container.
+	UserDefinedMetrics []UserDefinedMetric `json:"userDefinedMetrics,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	// Pressure Stall Information for the container's cgroup.
+	PSI *PSIStats `json:"psi,omitempty"`
+	// Stats for each hardware accelerator (e.g. GPU) assigned to the container, as reported by
+	// its device plugin.
+	Accelerators []AcceleratorStats `json:"accelerators,omitempty" patchStrategy:"merge" patchMergeKey:"deviceID"`
+}
+
+// AcceleratorStats holds a point-in-time view of a single hardware accelerator (e.g. a GPU)
+// assigned to a container, as reported by the device plugin that manages it.
+type AcceleratorStats struct {
+	// DeviceID is the device plugin's identifier for this accelerator, unique among the
+	// accelerators it manages.
+	DeviceID string `json:"deviceID"`
+	// Make is the accelerator's manufacturer, e.g. "nvidia", "amd", "intel", "habana".
+	Make string `json:"make"`
+	// Model is the accelerator's model, e.g. "Tesla-T4".
+	Model string `json:"model"`
+	// MemoryTotal is the accelerator's total onboard memory, in bytes.
+	MemoryTotal *uint64 `json:"memoryTotal,omitempty"`
+	// MemoryUsed is the accelerator memory currently in use, in bytes.
+	MemoryUsed *uint64 `json:"memoryUsed,omitempty"`
+	// DutyCycle is the percent of time since the last sample during which the accelerator was
+	// actively processing, in the range [0, 100].
+	DutyCycle *uint64 `json:"dutyCycle,omitempty"`
+}
+
+// CPUInstantStats contains an instantaneous (smoothed) view of CPU usage.
+type CPUInstantStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// Total CPU usage (sum of all cores), averaged over the sample window.
+	UsageNanoCores *uint64 `json:"usageNanoCores,omitempty"`
+	// Cumulative CPU usage (sum of all cores) since object creation.
+	UsageCoreNanoSeconds *uint64 `json:"usageCoreNanoSeconds,omitempty"`
+}
+
+// MemoryInstantStats contains an instantaneous view of memory usage.
+type MemoryInstantStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// Available memory for use, as defined by the workingSet metric minus evictable data.
+	AvailableBytes *uint64 `json:"availableBytes,omitempty"`
+	// Total memory in use. This includes all memory regardless of when it was accessed.
+	UsageBytes *uint64 `json:"usageBytes,omitempty"`
+	// The amount of working set memory.
+	WorkingSetBytes *uint64 `json:"workingSetBytes,omitempty"`
+	// Cumulative number of times that a usage counter hit its limit.
+	PageFaults *uint64 `json:"pageFaults,omitempty"`
+	// Cumulative number of major page faults.
+	MajorPageFaults *uint64 `json:"majorPageFaults,omitempty"`
+}
+
+// NetworkStats contains data about network resources, summarized across interfaces.
+type NetworkStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// InterfaceStats holds the total stats for the default interface. Retained, and inlined, for
+	// backwards compatibility with consumers that only understand a single network interface;
+	// new consumers should prefer Interfaces, which covers every interface on the pod or node.
+	InterfaceStats `json:",inline"`
+	// Stats for each of the pod's interfaces. The default interface's entry, if present, is the
+	// same data as the inlined InterfaceStats above.
+	Interfaces []InterfaceStats `json:"interfaces,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+// InterfaceStats contains resource value data about interface such as statistics for network
+// packets and bytes transmitted and received.
+type InterfaceStats struct {
+	// The name of the interface.
+	Name string `json:"name"`
+	// Cumulative count of bytes received.
+	RxBytes *uint64 `json:"rxBytes,omitempty"`
+	// Cumulative count of receive errors encountered.
+	RxErrors *uint64 `json:"rxErrors,omitempty"`
+	// Cumulative count of packets dropped while receiving.
+	RxDropped *uint64 `json:"rxDropped,omitempty"`
+	// Cumulative count of bytes transmitted.
+	TxBytes *uint64 `json:"txBytes,omitempty"`
+	// Cumulative count of transmit errors encountered.
+	TxErrors *uint64 `json:"txErrors,omitempty"`
+	// Cumulative count of packets dropped while transmitting.
+	TxDropped *uint64 `json:"txDropped,omitempty"`
+}
+
+// PSIStats contains Pressure Stall Information (PSI), which measures the time tasks spent stalled
+// on a scarce CPU, memory, or IO resource, as a more direct congestion signal than usage counters
+// like WorkingSetBytes.
+type PSIStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// CPU pressure stall information.
+	CPU *PSIData `json:"cpu,omitempty"`
+	// Memory pressure stall information.
+	Memory *PSIData `json:"memory,omitempty"`
+	// IO pressure stall information.
+	IO *PSIData `json:"io,omitempty"`
+}
+
+// PSIData holds the "some" and "full" pressure stall metrics for a single resource, as described
+// in https://docs.kernel.org/accounting/psi.html.
+type PSIData struct {
+	// Some indicates the share of time in which at least one task was stalled on this resource.
+	Some PSIStatsData `json:"some,omitempty"`
+	// Full indicates the share of time in which all non-idle tasks were stalled on this resource
+	// simultaneously, and no useful work could be done.
+	Full PSIStatsData `json:"full,omitempty"`
+}
+
+// PSIStatsData is a single set of PSI averages and cumulative stall time.
+type PSIStatsData struct {
+	// Average share of time stalled over the last 10 seconds.
+	Avg10 float64 `json:"avg10"`
+	// Average share of time stalled over the last 60 seconds.
+	Avg60 float64 `json:"avg60"`
+	// Average share of time stalled over the last 300 seconds.
+	Avg300 float64 `json:"avg300"`
+	// Total cumulative stall time.
+	Total *uint64 `json:"total,omitempty"`
+}
+
+// FsStats contains data about filesystem usage.
+type FsStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// AvailableBytes represents the storage space available for this filesystem.
+	AvailableBytes *uint64 `json:"availableBytes,omitempty"`
+	// CapacityBytes represents the total capacity of this filesystem.
+	CapacityBytes *uint64 `json:"capacityBytes,omitempty"`
+	// UsedBytes represents the bytes used for a specific task on the filesystem.
+	UsedBytes *uint64 `json:"usedBytes,omitempty"`
+	// InodesFree represents the free inodes in the filesystem.
+	InodesFree *uint64 `json:"inodesFree,omitempty"`
+	// Inodes represents the total inodes in the filesystem.
+	Inodes *uint64 `json:"inodes,omitempty"`
+	// InodesUsed represents the inodes used by the filesystem.
+	InodesUsed *uint64 `json:"inodesUsed,omitempty"`
+}
+
+// RlimitStats contains data about system-wide resource limits, reported once per node.
+type RlimitStats struct {
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// The max PID of the OS.
+	MaxPID *int64 `json:"maxpid,omitempty"`
+	// The number of running process (threads, precisely).
+	NumOfRunningProcesses *int64 `json:"curproc,omitempty"`
+}
+
+// VolumeStats contains data about volume usage.
+type VolumeStats struct {
+	// Embedded FsStats.
+	FsStats `json:",inline"`
+	// Name is the name given to the volume by the pod.
+	Name string `json:"name"`
+	// Reference to the corresponding PersistentVolumeClaim, if the volume is a PVC.
+	PVCRef *NonLocalObjectReference `json:"pvcRef,omitempty"`
+}
+
+// UserDefinedMetricType defines how the metric should be interpreted by consumers.
+type UserDefinedMetricType string
+
+const (
+	MetricGauge      UserDefinedMetricType = "gauge"
+	MetricCumulative UserDefinedMetricType = "cumulative"
+	MetricDelta      UserDefinedMetricType = "delta"
+)
+
+// UserDefinedMetric contains a user defined metric value and its associated metadata.
+type UserDefinedMetric struct {
+	// The name of the metric.
+	Name string `json:"name"`
+	// The type of the metric.
+	Type UserDefinedMetricType `json:"type"`
+	// Display Units for the stats.
+	Units string `json:"units"`
+	// The time at which these stats were updated.
+	Time unversioned.Time `json:"time"`
+	// The value of the metric.
+	Value resource.Quantity `json:"value"`
+}
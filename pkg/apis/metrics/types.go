@@ -86,6 +86,8 @@ type AggregateSample struct {
 	Filesystem []FilesystemMetrics `json:"filesystem,omitempty" patchStrategy:"merge" patchMergeKey:"device"`
 	// Metrics pertaining to network resources.
 	Network *NetworkMetrics `json:"network,omitempty"`
+	// Metrics pertaining to Pressure Stall Information (PSI).
+	Pressure *PressureMetrics `json:"pressure,omitempty"`
 }
 
 // PodSample contains a metric sample point of pod-level resources.
@@ -110,6 +112,8 @@ type ContainerSample struct {
 	// Metrics pertaining to filesystem usage.
 	// Organized by device name.
 	Filesystem []FilesystemMetrics `json:"filesystem,omitempty" patchStrategy:"merge" patchMergeKey:"device"`
+	// Metrics pertaining to Pressure Stall Information (PSI).
+	Pressure *PressureMetrics `json:"pressure,omitempty"`
 }
 
 // NetworkMetrics contains data about network resources.
@@ -170,6 +174,40 @@ type TCPMetrics struct {
 	Closing int64 `json:"closing"`
 }
 
+// PressureMetrics contains raw Pressure Stall Information (PSI), as read from the cgroup v2
+// cpu.pressure, memory.pressure, and io.pressure files.
+// See https://docs.kernel.org/accounting/psi.html.
+type PressureMetrics struct {
+	// Pressure stalling on CPU.
+	CPU *PSIMetrics `json:"cpu,omitempty"`
+	// Pressure stalling on memory.
+	Memory *PSIMetrics `json:"memory,omitempty"`
+	// Pressure stalling on IO.
+	IO *PSIMetrics `json:"io,omitempty"`
+}
+
+// PSIMetrics holds the "some" and "full" lines parsed from a single PSI file.
+type PSIMetrics struct {
+	// Some is the share of time in which at least one task was stalled on this resource.
+	Some PSILine `json:"some,omitempty"`
+	// Full is the share of time in which all non-idle tasks were stalled on this resource
+	// simultaneously, and no useful work could be done.
+	Full PSILine `json:"full,omitempty"`
+}
+
+// PSILine is one line (some/full) of a PSI file: the percentage of time stalled, averaged over
+// the last 10, 60, and 300 seconds, plus the cumulative stall time.
+type PSILine struct {
+	// Percentage of time stalled, averaged over the last 10 seconds.
+	Avg10 float64 `json:"avg10"`
+	// Percentage of time stalled, averaged over the last 60 seconds.
+	Avg60 float64 `json:"avg60"`
+	// Percentage of time stalled, averaged over the last 300 seconds.
+	Avg300 float64 `json:"avg300"`
+	// Total cumulative stall time.
+	Total resource.Quantity `json:"total,omitempty"`
+}
+
 // CPUMetrics contains data about CPU usage.
 type CPUMetrics struct {
 	Cumulative    CPUCumulativeMetrics    `json:"cumulative,omitempty"`
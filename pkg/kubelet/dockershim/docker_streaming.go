@@ -18,41 +18,295 @@ package dockershim
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"net/url"
+	"os/exec"
+	"strconv"
+	"sync"
 	"time"
 
+	dockertypes "github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+
 	"k8s.io/kubernetes/pkg/kubelet/dockertools"
 	"k8s.io/kubernetes/pkg/kubelet/server/streaming"
 	"k8s.io/kubernetes/pkg/util/term"
 )
 
+// dockerService implements the CRI streaming verbs (ExecSync/Exec/Attach/PortForward) against a
+// Docker Engine API client, handing the actual I/O off to streamingRuntime through the
+// streamingServer.
+type dockerService struct {
+	client dockertools.DockerInterface
+
+	// streamingServer prepares streaming endpoints and dispatches to streamingRuntime once a
+	// client connects. It is nil when streaming is disabled.
+	streamingServer streaming.Server
+}
+
 type streamingRuntime struct {
 	client dockertools.DockerInterface
+
+	mu sync.Mutex
+	// streams tracks the kind of each live Exec/Attach session, keyed by the streamID that
+	// identifies it to ResizableRuntime.Resize: the exec ID for Exec sessions (docker hands one
+	// out) and containerID itself for Attach sessions (docker has no separate ID to give one).
+	streams map[string]streamKind
 }
 
-var _ streaming.Runtime = &streamingRuntime{}
+// streamKind distinguishes an Exec session, whose resize must go through the exec-resize API,
+// from an Attach session, whose resize must go through the container-resize API.
+type streamKind int
 
-func (ds *streamingRuntime) Exec(containerID string, cmd []string, in io.Reader, out, err io.WriteCloser, tty bool, resize <-chan term.Size) error {
-	// FIXME - implemnet this.
-	return nil
+const (
+	streamKindExec streamKind = iota
+	streamKindAttach
+)
+
+func (ds *streamingRuntime) registerStream(streamID string, kind streamKind) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.streams == nil {
+		ds.streams = make(map[string]streamKind)
+	}
+	ds.streams[streamID] = kind
 }
 
-func (ds *streamingRuntime) Attach(containerID string, in io.Reader, out, err io.WriteCloser, resize <-chan term.Size) error {
-	// FIXME - implemnet this.
+func (ds *streamingRuntime) unregisterStream(streamID string) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	delete(ds.streams, streamID)
+}
+
+func (ds *streamingRuntime) streamKind(streamID string) (streamKind, bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	kind, ok := ds.streams[streamID]
+	return kind, ok
+}
+
+var _ streaming.ResizableRuntime = &streamingRuntime{}
+
+func (ds *streamingRuntime) Exec(containerID string, cmd []string, in io.Reader, out, errW io.WriteCloser, tty bool, resize <-chan term.Size) error {
+	ctx := context.Background()
+
+	createResp, err := ds.client.ContainerExecCreate(ctx, containerID, dockertypes.ExecConfig{
+		Cmd:          cmd,
+		Tty:          tty,
+		AttachStdin:  in != nil,
+		AttachStdout: true,
+		AttachStderr: !tty,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exec for container %q: %v", containerID, err)
+	}
+
+	attachResp, err := ds.client.ContainerExecAttach(ctx, createResp.ID, dockertypes.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return fmt.Errorf("failed to attach to exec %q in container %q: %v", createResp.ID, containerID, err)
+	}
+	defer attachResp.Close()
+
+	ds.registerStream(createResp.ID, streamKindExec)
+	defer ds.unregisterStream(createResp.ID)
+
+	resizeFn := func(size term.Size) error {
+		return ds.client.ContainerExecResize(ctx, createResp.ID, dockercontainer.ResizeOptions{
+			Height: uint(size.Height),
+			Width:  uint(size.Width),
+		})
+	}
+	if err := streamHijackedConn(attachResp, in, out, errW, tty, resize, resizeFn); err != nil {
+		return err
+	}
+
+	inspect, err := ds.client.ContainerExecInspect(ctx, createResp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect exec %q in container %q: %v", createResp.ID, containerID, err)
+	}
+	if inspect.ExitCode != 0 {
+		return &execExitError{cmd: cmd, exitCode: inspect.ExitCode}
+	}
 	return nil
 }
 
+func (ds *streamingRuntime) Attach(containerID string, in io.Reader, out, errW io.WriteCloser, resize <-chan term.Size) error {
+	ctx := context.Background()
+
+	attachResp, err := ds.client.ContainerAttach(ctx, containerID, dockertypes.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  in != nil,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach to container %q: %v", containerID, err)
+	}
+	defer attachResp.Close()
+
+	inspect, err := ds.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container %q: %v", containerID, err)
+	}
+
+	var resizeFn func(term.Size) error
+	if inspect.Config != nil && inspect.Config.Tty {
+		resizeFn = func(size term.Size) error {
+			return ds.client.ContainerResize(ctx, containerID, dockercontainer.ResizeOptions{
+				Height: uint(size.Height),
+				Width:  uint(size.Width),
+			})
+		}
+	}
+
+	tty := inspect.Config != nil && inspect.Config.Tty
+	ds.registerStream(containerID, streamKindAttach)
+	defer ds.unregisterStream(containerID)
+	return streamHijackedConn(attachResp, in, out, errW, tty, resize, resizeFn)
+}
+
+// Resize resizes the pty of the exec/attach session identified by streamID: for an Exec session
+// streamID is the exec ID handed back by ContainerExecCreate, and the resize must go through the
+// exec-resize API; for an Attach session streamID is containerID itself (docker hands out no
+// separate ID for an attach), and the resize must go through the container-resize API instead.
+func (ds *streamingRuntime) Resize(containerID, streamID string, size streaming.TerminalSize) error {
+	kind, ok := ds.streamKind(streamID)
+	if !ok {
+		return streaming.ErrStreamClosed
+	}
+
+	options := dockercontainer.ResizeOptions{
+		Height: uint(size.Height),
+		Width:  uint(size.Width),
+	}
+	ctx := context.Background()
+	switch kind {
+	case streamKindAttach:
+		return ds.client.ContainerResize(ctx, containerID, options)
+	default:
+		return ds.client.ContainerExecResize(ctx, streamID, options)
+	}
+}
+
 func (ds *streamingRuntime) PortForward(podSandboxID string, port int32, stream io.ReadWriteCloser) error {
-	// FIXME - implement this.
+	ctx := context.Background()
+	inspect, err := ds.client.ContainerInspect(ctx, podSandboxID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect pod sandbox %q: %v", podSandboxID, err)
+	}
+	if inspect.State == nil || inspect.State.Pid == 0 {
+		return fmt.Errorf("pod sandbox %q has no running process to forward into", podSandboxID)
+	}
+
+	// Docker has no native port-forward API, so we forward by nsenter-ing the sandbox's network
+	// namespace and piping the client's stream through socat to the target port.
+	cmd := exec.Command("nsenter", "--target", strconv.Itoa(inspect.State.Pid), "--net", "--",
+		"socat", "STDIO", fmt.Sprintf("TCP4:localhost:%d", port))
+	cmd.Stdin = stream
+	cmd.Stdout = stream
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to port forward to %q:%d: %v: %s", podSandboxID, port, err, stderr.String())
+	}
+	return nil
+}
+
+// streamHijackedConn copies in to the hijacked connection and the connection's output to out
+// (and err, when the stream is multiplexed), and relays resize events for the lifetime of the
+// call. It blocks until the remote side closes the connection.
+func streamHijackedConn(resp dockertypes.HijackedResponse, in io.Reader, out, errW io.WriteCloser, tty bool, resize <-chan term.Size, resizeFn func(term.Size) error) error {
+	var wg sync.WaitGroup
+	if in != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.Copy(resp.Conn, in)
+			resp.CloseWrite()
+		}()
+	}
+
+	if resize != nil && resizeFn != nil {
+		go func() {
+			for size := range resize {
+				_ = resizeFn(size)
+			}
+		}()
+	}
+
+	var err error
+	if tty {
+		_, err = io.Copy(out, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(out, errW, resp.Reader)
+	}
+
+	if in != nil {
+		wg.Wait()
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
 	return nil
 }
 
+// execExitError reports a nonzero exit code from a completed Exec call.
+type execExitError struct {
+	cmd      []string
+	exitCode int
+}
+
+func (e *execExitError) Error() string {
+	return fmt.Sprintf("command %q exited with code %d", e.cmd, e.exitCode)
+}
+
+func (e *execExitError) ExitStatus() int {
+	return e.exitCode
+}
+
 // ExecSync executes a command in the container, and returns the stdout output.
 // If command exits with a non-zero exit code, an error is returned.
 func (ds *dockerService) ExecSync(containerID string, cmd []string, timeout time.Duration) (stdout []byte, stderr []byte, err error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	createResp, err := ds.client.ContainerExecCreate(ctx, containerID, dockertypes.ExecConfig{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create exec for container %q: %v", containerID, err)
+	}
+
+	attachResp, err := ds.client.ContainerExecAttach(ctx, createResp.ID, dockertypes.ExecStartCheck{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to attach to exec %q in container %q: %v", createResp.ID, containerID, err)
+	}
+	defer attachResp.Close()
+
 	var stdoutBuffer, stderrBuffer bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdoutBuffer, &stderrBuffer, attachResp.Reader); err != nil && err != io.EOF {
+		return nil, nil, fmt.Errorf("failed to read exec %q output: %v", createResp.ID, err)
+	}
 
+	inspect, err := ds.client.ContainerExecInspect(ctx, createResp.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to inspect exec %q in container %q: %v", createResp.ID, containerID, err)
+	}
+	if inspect.ExitCode != 0 {
+		err = &execExitError{cmd: cmd, exitCode: inspect.ExitCode}
+	}
+	return stdoutBuffer.Bytes(), stderrBuffer.Bytes(), err
 }
 
 // Exec prepares a streaming endpoint to execute a command in the container, and returns the address.
@@ -60,6 +314,7 @@ func (ds *dockerService) Exec(containerID string, cmd []string, tty, stdin bool)
 	if ds.streamingServer == nil {
 		return nil, streaming.ErrorStreamingDisabled
 	}
+	return ds.streamingServer.GetExec(containerID, cmd, tty, stdin)
 }
 
 // Attach prepares a streaming endpoint to attach to a running container, and returns the address.
@@ -67,6 +322,7 @@ func (ds *dockerService) Attach(containerID string, stdin bool) (*url.URL, error
 	if ds.streamingServer == nil {
 		return nil, streaming.ErrorStreamingDisabled
 	}
+	return ds.streamingServer.GetAttach(containerID, stdin)
 }
 
 // PortForward prepares a streaming endpoint to forward ports from a PodSandbox, and returns the address.
@@ -74,4 +330,5 @@ func (ds *dockerService) PortForward(podSandboxID string, ports []int32) (*url.U
 	if ds.streamingServer == nil {
 		return nil, streaming.ErrorStreamingDisabled
 	}
+	return ds.streamingServer.GetPortForward(podSandboxID, ports)
 }
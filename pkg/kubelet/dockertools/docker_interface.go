@@ -0,0 +1,36 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"context"
+
+	dockertypes "github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+)
+
+// DockerInterface is the subset of the Docker Engine API client used by the CRI shim's
+// streaming Exec/Attach/PortForward implementations.
+type DockerInterface interface {
+	ContainerInspect(ctx context.Context, containerID string) (dockertypes.ContainerJSON, error)
+	ContainerExecCreate(ctx context.Context, containerID string, config dockertypes.ExecConfig) (dockertypes.IDResponse, error)
+	ContainerExecAttach(ctx context.Context, execID string, config dockertypes.ExecStartCheck) (dockertypes.HijackedResponse, error)
+	ContainerExecInspect(ctx context.Context, execID string) (dockertypes.ContainerExecInspect, error)
+	ContainerExecResize(ctx context.Context, execID string, options dockercontainer.ResizeOptions) error
+	ContainerResize(ctx context.Context, containerID string, options dockercontainer.ResizeOptions) error
+	ContainerAttach(ctx context.Context, containerID string, options dockertypes.ContainerAttachOptions) (dockertypes.HijackedResponse, error)
+}
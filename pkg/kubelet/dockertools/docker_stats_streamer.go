@@ -0,0 +1,301 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockertools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerclient "github.com/docker/docker/client"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/metrics"
+)
+
+// defaultRingSize bounds the number of ContainerSample entries retained per container, so a
+// container that's never read from doesn't grow its buffer without limit.
+const defaultRingSize = 60
+
+// ContainerStatsStreamer opens a Docker stats stream per container and maintains a bounded
+// ring buffer of the resulting ContainerSample history. It replaces polling cAdvisor for the
+// cases (HPA, admission) that need sub-second sampling.
+//
+// This supersedes periodic polling: a single long-lived stream per container is far cheaper
+// than repeatedly scraping cAdvisor, and delivers samples as soon as docker emits them.
+type ContainerStatsStreamer struct {
+	client dockerclient.ContainerAPIClient
+	step   int
+
+	mu      sync.Mutex
+	buffers map[string]*containerStatsBuffer
+}
+
+type containerStatsBuffer struct {
+	samples     []metrics.ContainerSample
+	subscribers []chan<- metrics.ContainerSample
+	cancel      func()
+}
+
+// NewContainerStatsStreamer creates a ContainerStatsStreamer backed by client. opts.Step
+// downsamples streamed frames before they're stored; a Step of 0 or 1 stores every frame.
+func NewContainerStatsStreamer(client dockerclient.ContainerAPIClient, opts metrics.MetricsOptions) *ContainerStatsStreamer {
+	step := opts.Step
+	if step < 1 {
+		step = 1
+	}
+	return &ContainerStatsStreamer{
+		client:  client,
+		step:    step,
+		buffers: make(map[string]*containerStatsBuffer),
+	}
+}
+
+// StartStreaming opens a stats stream for containerID and begins appending samples to its
+// buffer. It is a no-op if a stream for containerID is already open.
+func (s *ContainerStatsStreamer) StartStreaming(containerID string) error {
+	s.mu.Lock()
+	if _, ok := s.buffers[containerID]; ok {
+		s.mu.Unlock()
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	buf := &containerStatsBuffer{cancel: cancel}
+	s.buffers[containerID] = buf
+	s.mu.Unlock()
+
+	resp, err := s.client.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		cancel()
+		s.mu.Lock()
+		delete(s.buffers, containerID)
+		s.mu.Unlock()
+		return fmt.Errorf("failed to open stats stream for container %q: %v", containerID, err)
+	}
+
+	go s.consume(containerID, resp.Body)
+	return nil
+}
+
+// StopStreaming closes the stats stream for containerID, if one is open.
+func (s *ContainerStatsStreamer) StopStreaming(containerID string) {
+	s.mu.Lock()
+	buf, ok := s.buffers[containerID]
+	if ok {
+		delete(s.buffers, containerID)
+	}
+	s.mu.Unlock()
+	if ok {
+		buf.cancel()
+	}
+}
+
+// Subscribe returns a channel that receives every ContainerSample appended for containerID, and
+// a cancel func to unsubscribe. While the channel is full, new samples are dropped for this
+// subscriber (without blocking the streamer); the channel itself stays subscribed.
+func (s *ContainerStatsStreamer) Subscribe(containerID string) (<-chan metrics.ContainerSample, func()) {
+	ch := make(chan metrics.ContainerSample, defaultRingSize)
+
+	s.mu.Lock()
+	buf, ok := s.buffers[containerID]
+	if ok {
+		buf.subscribers = append(buf.subscribers, ch)
+	}
+	s.mu.Unlock()
+
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		buf, ok := s.buffers[containerID]
+		if !ok {
+			return
+		}
+		for i, sub := range buf.subscribers {
+			if sub == ch {
+				buf.subscribers = append(buf.subscribers[:i], buf.subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, cancel
+}
+
+// Samples returns the buffered ContainerSample history for containerID.
+func (s *ContainerStatsStreamer) Samples(containerID string) []metrics.ContainerSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.buffers[containerID]
+	if !ok {
+		return nil
+	}
+	samples := make([]metrics.ContainerSample, len(buf.samples))
+	copy(samples, buf.samples)
+	return samples
+}
+
+// consume reads StatsResponse frames from the docker stats stream, downsamples them according
+// to s.step, and appends the resulting ContainerSample to the container's ring buffer.
+func (s *ContainerStatsStreamer) consume(containerID string, body closerReader) {
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	frame := 0
+	for {
+		var resp dockercontainer.StatsResponse
+		if err := decoder.Decode(&resp); err != nil {
+			// Stream closed, either because the container exited or StopStreaming was called.
+			return
+		}
+
+		frame++
+		if frame%s.step != 0 {
+			continue
+		}
+
+		s.appendSample(containerID, containerSampleFromStatsResponse(resp))
+	}
+}
+
+func (s *ContainerStatsStreamer) appendSample(containerID string, sample metrics.ContainerSample) {
+	s.mu.Lock()
+	buf, ok := s.buffers[containerID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	buf.samples = append(buf.samples, sample)
+	if len(buf.samples) > defaultRingSize {
+		buf.samples = buf.samples[len(buf.samples)-defaultRingSize:]
+	}
+	subscribers := buf.subscribers
+	s.mu.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- sample:
+		default:
+			// Consumer is behind; drop the sample rather than blocking the streamer.
+		}
+	}
+}
+
+// Reap closes streams for any tracked container ID that's no longer present in liveContainers
+// (as reported by `docker ps`).
+func (s *ContainerStatsStreamer) Reap(liveContainers map[string]bool) {
+	s.mu.Lock()
+	var stale []string
+	for id := range s.buffers {
+		if !liveContainers[id] {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range stale {
+		s.StopStreaming(id)
+	}
+}
+
+// StartReaper runs Reap every period until stopCh is closed, using listContainers to enumerate
+// the set of live container IDs.
+func (s *ContainerStatsStreamer) StartReaper(period time.Duration, listContainers func() (map[string]bool, error), stopCh <-chan struct{}) {
+	go wait.Until(func() {
+		live, err := listContainers()
+		if err != nil {
+			return
+		}
+		s.Reap(live)
+	}, period, stopCh)
+}
+
+// closerReader is satisfied by the io.ReadCloser docker's ContainerStats response body.
+type closerReader interface {
+	Read(p []byte) (n int, err error)
+	Close() error
+}
+
+func containerSampleFromStatsResponse(resp dockercontainer.StatsResponse) metrics.ContainerSample {
+	sample := metrics.ContainerSample{
+		SampleTime: unversioned.NewTime(resp.Read),
+		CPU: &metrics.CPUMetrics{
+			Cumulative: metrics.CPUCumulativeMetrics{
+				TotalCoreSeconds:  nanosToCoreSeconds(resp.CPUStats.CPUUsage.TotalUsage),
+				UserCoreSeconds:   nanosToCoreSeconds(resp.CPUStats.CPUUsage.UsageInUsermode),
+				SystemCoreSeconds: nanosToCoreSeconds(resp.CPUStats.CPUUsage.UsageInKernelmode),
+			},
+		},
+		Memory: &metrics.MemoryMetrics{
+			TotalBytes: *resource.NewQuantity(int64(resp.MemoryStats.Usage), resource.BinarySI),
+		},
+	}
+
+	sample.DiskIO = diskIOFromBlkioStats(resp.BlkioStats)
+
+	return sample
+}
+
+// diskIOFromBlkioStats flattens docker's per-device, per-op blkio entries into one DiskIOMetrics
+// per device. Network stats aren't carried here: ContainerSample has no Network field, since
+// per-container network accounting is reported at the pod level via PodSample.
+func diskIOFromBlkioStats(blkio dockercontainer.BlkioStats) []metrics.DiskIOMetrics {
+	byDevice := map[string]*metrics.DiskIOMetrics{}
+	deviceFor := func(major, minor uint64) *metrics.DiskIOMetrics {
+		device := fmt.Sprintf("%d:%d", major, minor)
+		d, ok := byDevice[device]
+		if !ok {
+			d = &metrics.DiskIOMetrics{Device: device}
+			byDevice[device] = d
+		}
+		return d
+	}
+
+	for _, entry := range blkio.IoServiceBytesRecursive {
+		d := deviceFor(entry.Major, entry.Minor)
+		addIOOperationValue(&d.IOServiceBytes, entry.Op, entry.Value)
+	}
+
+	result := make([]metrics.DiskIOMetrics, 0, len(byDevice))
+	for _, d := range byDevice {
+		result = append(result, *d)
+	}
+	return result
+}
+
+func nanosToCoreSeconds(nanos uint64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(nanos/1e6), resource.DecimalSI)
+}
+
+// addIOOperationValue accumulates a single blkio stat entry's value into the matching field of
+// an IOOperationMetrics, and into Total regardless of op.
+func addIOOperationValue(m *metrics.IOOperationMetrics, op string, value uint64) {
+	q := *resource.NewQuantity(int64(value), resource.DecimalSI)
+	m.Total.Add(q)
+	switch op {
+	case "Read":
+		m.Read.Add(q)
+	case "Write":
+		m.Write.Add(q)
+	case "Sync":
+		m.Sync.Add(q)
+	case "Async":
+		m.Async.Add(q)
+	}
+}
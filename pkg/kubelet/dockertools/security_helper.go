@@ -25,15 +25,17 @@ import (
 	"path/filepath"
 	"strings"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/pkg/api"
 	kubecontainer "k8s.io/kubernetes/pkg/kubelet/container"
 	"k8s.io/kubernetes/pkg/security/apparmor"
 )
 
 type OptsHelper interface {
-	// Get the security options for the container named ctrName according to the annotations.
-	// TODO: Rethink this API once security features are moved out of annotations.
-	GetSecurityOpts(annotations map[string]string, ctrName string) ([]DockerOpt, error)
+	// Get the security options for the container named ctrName. podSC and containerSC are the
+	// effective pod- and container-level security contexts (either may be nil); annotations are
+	// consulted only as a deprecated fallback for fields that predate the typed security context.
+	GetSecurityOpts(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext, annotations map[string]string, ctrName string) ([]DockerOpt, error)
 	FmtDockerOpts([]DockerOpt) ([]string, error)
 }
 
@@ -41,7 +43,7 @@ func NewOptsHelper(
 	apiVersion kubecontainer.Version,
 	appArmorValidator apparmor.Validator,
 	seccompProfileRoot string,
-) {
+) OptsHelper {
 	return &optsHelper{
 		apiVersion:         apiVersion,
 		appArmorValidator:  appArmorValidator,
@@ -54,6 +56,12 @@ type DockerOpt struct {
 	Key, Value string
 	// The alternative value to use in log/event messages.
 	Msg string
+	// ExplicitlyRequested is true when this option reflects a value the pod spec (or a
+	// deprecated annotation) explicitly asked for, as opposed to one we're emitting only because
+	// nothing was requested and this happens to be what we'd otherwise default to. It lets
+	// validateDockerOpts tell an explicit request for an unconfined seccomp profile (which really
+	// is incompatible with no-new-privileges) apart from the implicit default (which isn't).
+	ExplicitlyRequested bool
 }
 
 const (
@@ -66,8 +74,15 @@ const (
 )
 
 var (
-	// Default set of seccomp security options.
-	defaultSeccompOpt = []DockerOpt{{"seccomp", "unconfined", ""}}
+	// explicitUnconfinedSeccompOpt is used when the pod explicitly asked for an unconfined
+	// seccomp profile, via the typed SeccompProfileTypeUnconfined or the "unconfined" annotation.
+	explicitUnconfinedSeccompOpt = []DockerOpt{{Key: "seccomp", Value: "unconfined", ExplicitlyRequested: true}}
+
+	// implicitUnconfinedSeccompOpt is used when no seccomp profile was requested at all; docker
+	// would otherwise apply its own default profile, so we make the historical "unconfined by
+	// default" behavior explicit, but since the pod never asked for this it's not treated as
+	// conflicting with no-new-privileges the way an explicit request would be.
+	implicitUnconfinedSeccompOpt = []DockerOpt{{Key: "seccomp", Value: "unconfined"}}
 )
 
 type optsHelper struct {
@@ -77,6 +92,10 @@ type optsHelper struct {
 }
 
 func (h *optsHelper) FmtDockerOpts(opts []DockerOpt) ([]string, error) {
+	if err := validateDockerOpts(opts); err != nil {
+		return nil, err
+	}
+
 	sep := optSeparatorNew
 	if result, err := h.apiVersion.Compare(optSeparatorChangeVersion); err != nil {
 		return nil, err
@@ -86,14 +105,38 @@ func (h *optsHelper) FmtDockerOpts(opts []DockerOpt) ([]string, error) {
 
 	fmtOpts := make([]string, len(opts))
 	for i, opt := range opts {
-		fmtOpts[i] = fmt.Sprintf("%s%c%s", opt.key, sep, opt.value)
+		fmtOpts[i] = fmt.Sprintf("%s%c%s", opt.Key, sep, opt.Value)
 	}
 	return fmtOpts, nil
 }
 
-func (h *optsHelper) GetSecurityOpts(annotations map[string]string, ctrName string) ([]DockerOpt, error) {
+// validateDockerOpts rejects combinations of security options that docker would otherwise accept
+// but that don't match the pod's declared intent (e.g. a localhost seccomp profile with no file,
+// or disabling privilege escalation while leaving seccomp unconfined).
+func validateDockerOpts(opts []DockerOpt) error {
+	var seccomp, noNewPrivileges *DockerOpt
+	for i := range opts {
+		switch opts[i].Key {
+		case "seccomp":
+			seccomp = &opts[i]
+		case "no-new-privileges":
+			noNewPrivileges = &opts[i]
+		}
+	}
+
+	if seccomp != nil && seccomp.Value == "" {
+		return fmt.Errorf("invalid seccomp option: empty profile")
+	}
+	if noNewPrivileges != nil && seccomp != nil && seccomp.Value == "unconfined" && seccomp.ExplicitlyRequested {
+		return fmt.Errorf("invalid security options: no-new-privileges cannot be combined with an unconfined seccomp profile")
+	}
+
+	return nil
+}
+
+func (h *optsHelper) GetSecurityOpts(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext, annotations map[string]string, ctrName string) ([]DockerOpt, error) {
 	var securityOpts []DockerOpt
-	if seccompOpts, err := h.getSeccompOpts(annotations, ctrName); err != nil {
+	if seccompOpts, err := h.getSeccompOpts(podSC, containerSC, annotations, ctrName); err != nil {
 		return nil, err
 	} else {
 		securityOpts = append(securityOpts, seccompOpts...)
@@ -105,11 +148,31 @@ func (h *optsHelper) GetSecurityOpts(annotations map[string]string, ctrName stri
 		securityOpts = append(securityOpts, appArmorOpts...)
 	}
 
+	if labelOpts := getSELinuxLabelOpts(podSC, containerSC); len(labelOpts) > 0 {
+		securityOpts = append(securityOpts, labelOpts...)
+	}
+
+	if noNewPrivsOpt := getNoNewPrivilegesOpt(containerSC); noNewPrivsOpt != nil {
+		securityOpts = append(securityOpts, *noNewPrivsOpt)
+	}
+
 	return securityOpts, nil
 }
 
+// effectiveSeccompProfile returns the container's SeccompProfile if set, falling back to the
+// pod's SeccompProfile.
+func effectiveSeccompProfile(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext) *v1.SeccompProfile {
+	if containerSC != nil && containerSC.SeccompProfile != nil {
+		return containerSC.SeccompProfile
+	}
+	if podSC != nil && podSC.SeccompProfile != nil {
+		return podSC.SeccompProfile
+	}
+	return nil
+}
+
 // Get the docker security options for seccomp.
-func (h *optsHelper) getSeccompOpts(annotations map[string]string, ctrName string) ([]DockerOpt, error) {
+func (h *optsHelper) getSeccompOpts(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext, annotations map[string]string, ctrName string) ([]DockerOpt, error) {
 	// seccomp is only on docker versions >= v1.10
 	if result, err := h.apiVersion.Compare(minSeccompAPIVersion); err != nil {
 		return nil, err
@@ -117,31 +180,35 @@ func (h *optsHelper) getSeccompOpts(annotations map[string]string, ctrName strin
 		return nil, nil // return early for Docker < 1.10
 	}
 
-	profile, profileOK := annotations[api.SeccompContainerAnnotationKeyPrefix+ctrName]
-	if !profileOK {
-		// try the pod profile
-		profile, profileOK = annotations[api.SeccompPodAnnotationKey]
-		if !profileOK {
-			// return early the default
-			return defaultSeccompOpt, nil
-		}
+	if profile := effectiveSeccompProfile(podSC, containerSC); profile != nil {
+		return h.seccompOptsFromProfile(profile)
 	}
 
-	if profile == "unconfined" {
-		// return early the default
-		return defaultSeccompOpt, nil
-	}
+	// Deprecated: fall back to the seccomp annotations if no typed profile was set.
+	return h.getSeccompOptsFromAnnotations(annotations, ctrName)
+}
 
-	if profile == "docker/default" {
+func (h *optsHelper) seccompOptsFromProfile(profile *v1.SeccompProfile) ([]DockerOpt, error) {
+	switch profile.Type {
+	case v1.SeccompProfileTypeUnconfined:
+		return explicitUnconfinedSeccompOpt, nil
+
+	case v1.SeccompProfileTypeRuntimeDefault:
 		// return nil so docker will load the default seccomp profile
 		return nil, nil
-	}
 
-	if !strings.HasPrefix(profile, "localhost/") {
-		return nil, fmt.Errorf("unknown seccomp profile option: %s", profile)
+	case v1.SeccompProfileTypeLocalhost:
+		if profile.LocalhostProfile == nil || *profile.LocalhostProfile == "" {
+			return nil, fmt.Errorf("seccomp profile type Localhost requires a localhostProfile")
+		}
+		return h.loadLocalhostSeccompProfile(*profile.LocalhostProfile)
+
+	default:
+		return nil, fmt.Errorf("unknown seccomp profile type: %q", profile.Type)
 	}
+}
 
-	name := strings.TrimPrefix(profile, "localhost/") // by pod annotation validation, name is a valid subpath
+func (h *optsHelper) loadLocalhostSeccompProfile(name string) ([]DockerOpt, error) {
 	fname := filepath.Join(h.seccompProfileRoot, filepath.FromSlash(name))
 	file, err := ioutil.ReadFile(fname)
 	if err != nil {
@@ -155,7 +222,38 @@ func (h *optsHelper) getSeccompOpts(annotations map[string]string, ctrName strin
 	// Rather than the full profile, just put the filename & md5sum in the event log.
 	msg := fmt.Sprintf("%s(md5:%x)", name, md5.Sum(file))
 
-	return []DockerOpt{{"seccomp", b.String(), msg}}, nil
+	return []DockerOpt{{Key: "seccomp", Value: b.String(), Msg: msg}}, nil
+}
+
+// Deprecated: seccomp annotations were superseded by the typed SeccompProfile field and are kept
+// only as a fallback for pods created before the field existed.
+func (h *optsHelper) getSeccompOptsFromAnnotations(annotations map[string]string, ctrName string) ([]DockerOpt, error) {
+	profile, profileOK := annotations[api.SeccompContainerAnnotationKeyPrefix+ctrName]
+	if !profileOK {
+		// try the pod profile
+		profile, profileOK = annotations[api.SeccompPodAnnotationKey]
+		if !profileOK {
+			// nothing requested: fall back to the implicit default, not a rejectable request
+			return implicitUnconfinedSeccompOpt, nil
+		}
+	}
+
+	if profile == "unconfined" {
+		// explicitly requested
+		return explicitUnconfinedSeccompOpt, nil
+	}
+
+	if profile == "docker/default" {
+		// return nil so docker will load the default seccomp profile
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(profile, "localhost/") {
+		return nil, fmt.Errorf("unknown seccomp profile option: %s", profile)
+	}
+
+	name := strings.TrimPrefix(profile, "localhost/") // by pod annotation validation, name is a valid subpath
+	return h.loadLocalhostSeccompProfile(name)
 }
 
 // Get the docker security options for AppArmor.
@@ -171,5 +269,49 @@ func (h *optsHelper) getAppArmorOpts(annotations map[string]string, ctrName stri
 	}
 
 	profileName := strings.TrimPrefix(profile, apparmor.ProfileNamePrefix)
-	return []DockerOpt{{"apparmor", profileName, ""}}, nil
+	return []DockerOpt{{Key: "apparmor", Value: profileName}}, nil
+}
+
+// effectiveSELinuxOptions returns the container's SELinuxOptions if set, falling back to the
+// pod's SELinuxOptions.
+func effectiveSELinuxOptions(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext) *v1.SELinuxOptions {
+	if containerSC != nil && containerSC.SELinuxOptions != nil {
+		return containerSC.SELinuxOptions
+	}
+	if podSC != nil && podSC.SELinuxOptions != nil {
+		return podSC.SELinuxOptions
+	}
+	return nil
+}
+
+// getSELinuxLabelOpts translates SELinuxOptions into the docker "label" security options.
+func getSELinuxLabelOpts(podSC *v1.PodSecurityContext, containerSC *v1.SecurityContext) []DockerOpt {
+	selinux := effectiveSELinuxOptions(podSC, containerSC)
+	if selinux == nil {
+		return nil
+	}
+
+	var opts []DockerOpt
+	if selinux.User != "" {
+		opts = append(opts, DockerOpt{Key: "label", Value: "user:" + selinux.User})
+	}
+	if selinux.Role != "" {
+		opts = append(opts, DockerOpt{Key: "label", Value: "role:" + selinux.Role})
+	}
+	if selinux.Type != "" {
+		opts = append(opts, DockerOpt{Key: "label", Value: "type:" + selinux.Type})
+	}
+	if selinux.Level != "" {
+		opts = append(opts, DockerOpt{Key: "label", Value: "level:" + selinux.Level})
+	}
+	return opts
+}
+
+// getNoNewPrivilegesOpt returns the docker "no-new-privileges" option when the container has
+// explicitly disabled privilege escalation.
+func getNoNewPrivilegesOpt(containerSC *v1.SecurityContext) *DockerOpt {
+	if containerSC == nil || containerSC.AllowPrivilegeEscalation == nil || *containerSC.AllowPrivilegeEscalation {
+		return nil
+	}
+	return &DockerOpt{Key: "no-new-privileges", Value: "true", ExplicitlyRequested: true}
 }
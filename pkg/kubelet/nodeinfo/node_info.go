@@ -19,12 +19,44 @@ package nodeinfo
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/golang/glog"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// defaultResyncInterval is how often the background reconciler re-fetches the node object to
+// detect changes that a watch might have missed (e.g. across an API-server outage).
+const defaultResyncInterval = 1 * time.Minute
+
+// subscriberBacklog bounds how many events a Subscribe channel can queue before it's considered
+// unresponsive and dropped.
+const subscriberBacklog = 10
+
+// NodeEventType describes the kind of change a NodeEvent reports.
+type NodeEventType string
+
+const (
+	NodeAdded    NodeEventType = "Added"
+	NodeModified NodeEventType = "Modified"
+	NodeDeleted  NodeEventType = "Deleted"
+)
+
+// NodeEvent describes a change to the watched node object.
+type NodeEvent struct {
+	Type    NodeEventType
+	OldNode *v1.Node
+	NewNode *v1.Node
+}
+
+// CancelFunc unsubscribes a channel returned by Provider.Subscribe. It is safe to call more than
+// once.
+type CancelFunc func()
+
 type Provider interface {
 	// GetNode returns the node object, or an approximation when the real API
 	// object is unavailable.
@@ -38,6 +70,15 @@ type Provider interface {
 	// Specifically, it spoofs the node UID when the real UID is unavailable.
 	// See https://github.com/kubernetes/kubernetes/issues/42701 for background.
 	GetEventRef() *v1.ObjectReference
+
+	// Subscribe returns a channel of NodeEvents for changes to the watched node, and a
+	// CancelFunc to stop receiving them. The channel is closed and dropped if the caller falls
+	// behind; callers that need a guaranteed delivery should call GetNode instead.
+	Subscribe() (<-chan NodeEvent, CancelFunc)
+
+	// Run starts the background reconciler that powers Subscribe and refreshes the cached node
+	// UID. It blocks until stopCh is closed, so it's normally invoked in its own goroutine.
+	Run(stopCh <-chan struct{})
 }
 
 type provider struct {
@@ -51,6 +92,16 @@ type provider struct {
 
 	// Cached UID value
 	nodeUID atomic.Value // type types.UID
+
+	// Cached copy of the last node object observed by the reconciler, used to compute
+	// Added/Modified/Deleted transitions and to reconcile the initial placeholder node.
+	lastNode atomic.Value // type *v1.Node
+
+	resyncInterval time.Duration
+
+	subscribersMu    sync.Mutex
+	nextSubscriberID int
+	subscribers      map[int]chan NodeEvent
 }
 
 var _ Provider = &provider{}
@@ -62,23 +113,20 @@ type NodeGetter interface {
 	Get(name string) (*v1.Node, error)
 }
 
-// NewProvider instantiates a new node info provider.
-func NewProvider(nodeName string, nodeGetter NodeGetter) Provider {
+// NewProvider instantiates a new node info provider. initialNodeFn approximates a node object
+// from the available information when the API server can't be reached; it may be nil if no
+// approximation is available, in which case GetNode returns an error until the API server is
+// reachable.
+func NewProvider(nodeName string, nodeGetter NodeGetter, initialNodeFn func() (*v1.Node, error)) Provider {
 	return &provider{
-		nodeName:   nodeName,
-		nodeGetter: nodeGetter,
+		nodeName:       nodeName,
+		nodeGetter:     nodeGetter,
+		initialNodeFn:  initialNodeFn,
+		resyncInterval: defaultResyncInterval,
+		subscribers:    make(map[int]chan NodeEvent),
 	}
 }
 
-// SetInitialNodeFn sets the function that's used to construct the initial node
-// object.
-// TODO: This function is only necessary because of a circular dependency with
-// the kubelet initialization. Ideally the dependency initialization would be
-// reordered so this can be passed in to NewProvider instead.
-func (p *provider) SetInitialNodeFn(initialNodeFn func() (*v1.Node, error)) {
-	p.initialNodeFn = initialNodeFn
-}
-
 // GetNode implements Provider.
 // When the real node object cannot be fetched, the initialNodeFn function is
 // used to generate (and cache) an approximate node.
@@ -119,6 +167,99 @@ func (p *provider) GetEventRef() *v1.ObjectReference {
 	}
 }
 
+// Subscribe implements Provider.
+func (p *provider) Subscribe() (<-chan NodeEvent, CancelFunc) {
+	ch := make(chan NodeEvent, subscriberBacklog)
+
+	p.subscribersMu.Lock()
+	id := p.nextSubscriberID
+	p.nextSubscriberID++
+	p.subscribers[id] = ch
+	p.subscribersMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			p.subscribersMu.Lock()
+			defer p.subscribersMu.Unlock()
+			// publish may already have dropped and closed a subscriber that fell behind.
+			if _, ok := p.subscribers[id]; ok {
+				delete(p.subscribers, id)
+				close(ch)
+			}
+		})
+	}
+	return ch, cancel
+}
+
+// Run implements Provider. It polls the NodeGetter every resyncInterval, publishing
+// Added/Modified/Deleted NodeEvents and keeping the cached UID and initial-node placeholder in
+// sync with the observed object.
+func (p *provider) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(p.resyncInterval)
+	defer ticker.Stop()
+
+	p.reconcile()
+	for {
+		select {
+		case <-ticker.C:
+			p.reconcile()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// reconcile fetches the current node object and, if it differs from the last observed object,
+// publishes a NodeEvent, refreshes the cached UID, and reconciles the initial-node placeholder
+// with the real object once the API server is reachable again.
+func (p *provider) reconcile() {
+	n, err := p.nodeGetter.Get(p.nodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if old, _ := p.lastNode.Load().(*v1.Node); old != nil {
+				p.lastNode.Store((*v1.Node)(nil))
+				p.publish(NodeEvent{Type: NodeDeleted, OldNode: old})
+			}
+			return
+		}
+		// API server outage: the cached state is left as is. GetNode falls back to
+		// getInitialNode, which serves the last real node stored below, or the initialNodeFn
+		// placeholder if no real node has been observed yet; initialNodeFn is not re-invoked
+		// once a node is cached.
+		return
+	}
+
+	p.nodeUID.Store(n.UID)
+
+	old, _ := p.lastNode.Load().(*v1.Node)
+	p.lastNode.Store(n)
+
+	// Now that we have a real object, stop serving the placeholder from getInitialNode.
+	p.initialNode.Store(n)
+
+	switch {
+	case old == nil:
+		p.publish(NodeEvent{Type: NodeAdded, NewNode: n})
+	case old.ResourceVersion != n.ResourceVersion:
+		p.publish(NodeEvent{Type: NodeModified, OldNode: old, NewNode: n})
+	}
+}
+
+func (p *provider) publish(event NodeEvent) {
+	p.subscribersMu.Lock()
+	defer p.subscribersMu.Unlock()
+	for id, ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+			glog.Warningf("nodeinfo: dropping subscriber %d for node %q; channel is full", id, p.nodeName)
+			delete(p.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
 func (p *provider) getUID() (types.UID, error) {
 	cached := p.nodeUID.Load()
 	if cached != nil {
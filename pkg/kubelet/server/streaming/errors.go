@@ -25,15 +25,25 @@ import (
 
 var (
 	ErrorStreamingDisabled = errors.New("streaming methods disabled")
+	// ErrResizeUnsupported is returned by ResizableRuntime.Resize when the runtime has no way to
+	// resize an in-flight Exec/Attach stream.
+	ErrResizeUnsupported = errors.New("resize is not supported by this runtime")
+	// ErrStreamClosed is returned by ResizableRuntime.Resize when streamID no longer refers to a
+	// live Exec/Attach stream.
+	ErrStreamClosed = errors.New("stream closed")
 )
 
 func GRPCError(err error) error {
 	var code codes.Code
 	switch err {
 	case ErrorStreamingDisabled:
-		code = codes.Unknown
+		code = codes.FailedPrecondition
+	case ErrResizeUnsupported:
+		code = codes.Unimplemented
+	case ErrStreamClosed:
+		code = codes.Canceled
 	default:
 		code = codes.Unknown
 	}
-	return grpc.Errorf(code, err.String)
+	return grpc.Errorf(code, err.Error())
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"k8s.io/kubernetes/pkg/util/term"
+)
+
+// TerminalSize is the wire representation of a client's resize request, decoded from a SPDY
+// resize frame or a WebSocket resize message before it is handed to a ResizeQueue.
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// ResizeQueue takes TerminalSize events already decoded off a stream's wire protocol (it does no
+// decoding itself) and republishes them as
+// term.Size on a channel a Runtime's Exec/Attach can range over for the lifetime of the call.
+// Resize events are coalesced into a single slot: Resize never blocks, and a slow or stalled
+// reader always finds the most recently decoded size waiting on Channel rather than a stale one.
+type ResizeQueue struct {
+	sizes chan term.Size
+}
+
+// NewResizeQueue returns a ResizeQueue ready to have resize events pushed onto it.
+func NewResizeQueue() *ResizeQueue {
+	return &ResizeQueue{sizes: make(chan term.Size, 1)}
+}
+
+// Resize records a newly decoded resize event without blocking the caller (typically the
+// connection's read loop), discarding whatever size was previously queued and not yet read so
+// Channel always yields the latest size rather than backing up behind a slow reader.
+func (q *ResizeQueue) Resize(size TerminalSize) {
+	newSize := term.Size{Width: size.Width, Height: size.Height}
+	select {
+	case q.sizes <- newSize:
+		return
+	default:
+	}
+	select {
+	case <-q.sizes:
+	default:
+	}
+	select {
+	case q.sizes <- newSize:
+	default:
+	}
+}
+
+// Channel returns the channel of resize events, suitable for passing as the resize channel of
+// Runtime.Exec or Runtime.Attach.
+func (q *ResizeQueue) Channel() <-chan term.Size {
+	return q.sizes
+}
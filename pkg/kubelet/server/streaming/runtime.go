@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"io"
+	"net/url"
+
+	"k8s.io/kubernetes/pkg/util/term"
+)
+
+// Runtime is implemented by container runtimes that support the streaming Exec/Attach/
+// PortForward verbs. The streaming Server calls into a Runtime to perform the actual I/O once a
+// client has connected to the pre-authorized streaming URL the Server handed out.
+type Runtime interface {
+	// Exec executes cmd in the container and streams the result via in/out/err. If tty is true,
+	// resize carries terminal resize events for the lifetime of the call.
+	Exec(containerID string, cmd []string, in io.Reader, out, err io.WriteCloser, tty bool, resize <-chan term.Size) error
+
+	// Attach streams the container's running process via in/out/err.
+	Attach(containerID string, in io.Reader, out, err io.WriteCloser, resize <-chan term.Size) error
+
+	// PortForward copies data between stream and the given port of the pod sandbox.
+	PortForward(podSandboxID string, port int32, stream io.ReadWriteCloser) error
+}
+
+// ResizableRuntime is an optional capability a Runtime can implement to resize an already-running
+// Exec/Attach stream by ID, as an alternative to only reading resize events off the channel
+// passed to Exec/Attach at stream-creation time. The CRI shim implements this using the runtime's
+// own exec/attach session ID as streamID.
+type ResizableRuntime interface {
+	Runtime
+	// Resize resizes the terminal of the Exec/Attach stream identified by streamID to size. It
+	// returns ErrResizeUnsupported if the runtime has no way to resize an in-flight stream, and
+	// ErrStreamClosed if streamID no longer refers to a live stream.
+	Resize(containerID, streamID string, size TerminalSize) error
+}
+
+// Server prepares streaming endpoints for Exec/Attach/PortForward requests and returns the
+// address a client should connect to in order to perform the actual I/O against a Runtime.
+type Server interface {
+	GetExec(containerID string, cmd []string, tty, stdin bool) (*url.URL, error)
+	GetAttach(containerID string, stdin bool) (*url.URL, error)
+	GetPortForward(podSandboxID string, ports []int32) (*url.URL, error)
+}
@@ -25,10 +25,17 @@ import (
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/component-base/version"
 	"k8s.io/kubernetes/pkg/probe"
+	"k8s.io/kubernetes/pkg/util/trustedresources"
 )
 
+// TrustedSourceAnnotationPrefix is the trustedresources.Keyring.AnnotationPrefix used to verify a
+// probe source object (e.g. a ConfigMap or CRD holding the HTTPGetAction) before a probe built
+// from it is ever executed.
+const TrustedSourceAnnotationPrefix = "probes.kubernetes.io"
+
 func NewProbeRequest(url *url.URL, headers http.Header) (*http.Request, error) {
 	req, err := http.NewRequest("GET", url.String(), nil)
 	if err != nil {
@@ -56,21 +63,63 @@ func NewProbeRequest(url *url.URL, headers http.Header) (*http.Request, error) {
 	return req, nil
 }
 
+// NewRequestForHTTPGetAction builds a GET request against a single pod IP. On dual-stack pods,
+// prefer NewRequestsForHTTPGetAction, which builds one request per IP family so the worker can
+// fall back to the container's other family if its preferred one doesn't answer.
 func NewRequestForHTTPGetAction(httpGet *v1.HTTPGetAction, container *v1.Container, podIP string) (*http.Request, error) {
-	scheme := strings.ToLower(string(httpGet.Scheme))
-	host := httpGet.Host
-	if host == "" {
-		host = podIP
+	reqs, err := NewRequestsForHTTPGetAction(httpGet, container, []string{podIP})
+	if err != nil {
+		return nil, err
 	}
+	return reqs[0], nil
+}
+
+// NewRequestsForHTTPGetAction returns one GET request per entry in podIPs, in the same order, so
+// a dual-stack pod's worker can try each family in turn (or race them) before reporting failure.
+// If httpGet.Host is set, it pins the probe to that host/IP directly and podIPs is ignored,
+// matching the existing single-request behavior.
+func NewRequestsForHTTPGetAction(httpGet *v1.HTTPGetAction, container *v1.Container, podIPs []string) ([]*http.Request, error) {
+	scheme := strings.ToLower(string(httpGet.Scheme))
 	port, err := probe.ResolveContainerPort(httpGet.Port, container)
 	if err != nil {
 		return nil, err
 	}
 	path := httpGet.Path
-	url := formatURL(scheme, host, port, path)
 	headers := v1HeaderToHttpHeader(httpGet.HTTPHeaders)
 
-	return NewProbeRequest(url, headers)
+	if httpGet.Host != "" {
+		req, err := NewProbeRequest(formatURL(scheme, httpGet.Host, port, path), headers)
+		if err != nil {
+			return nil, err
+		}
+		return []*http.Request{req}, nil
+	}
+	if len(podIPs) == 0 {
+		return nil, fmt.Errorf("no pod IPs available for HTTP probe")
+	}
+
+	reqs := make([]*http.Request, 0, len(podIPs))
+	for _, podIP := range podIPs {
+		req, err := NewProbeRequest(formatURL(scheme, podIP, port, path), headers)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// VerifyAndNewRequestForHTTPGetAction is NewRequestForHTTPGetAction, preceded by a
+// trustedresources.Verify check of source against raw. Use this instead of
+// NewRequestForHTTPGetAction when httpGet wasn't read directly off the Pod spec but resolved from
+// an external probe source (a ConfigMap or CRD) that could have been tampered with in transit or
+// at rest. Pass a keyring with AnnotationPrefix set to TrustedSourceAnnotationPrefix and no Keys
+// to only check the digest annotation.
+func VerifyAndNewRequestForHTTPGetAction(source metav1.Object, raw []byte, keyring trustedresources.Keyring, httpGet *v1.HTTPGetAction, container *v1.Container, podIP string) (*http.Request, error) {
+	if err := trustedresources.Verify(source, raw, keyring); err != nil {
+		return nil, fmt.Errorf("refusing to probe from untrusted source: %w", err)
+	}
+	return NewRequestForHTTPGetAction(httpGet, container, podIP)
 }
 
 // formatURL formats a URL from args.  For testability.
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kubernetes/pkg/util/trustedresources"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequestForHTTPGetAction(t *testing.T) {
+	httpGet := &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}
+	container := &v1.Container{Ports: []v1.ContainerPort{{ContainerPort: 8080}}}
+
+	req, err := NewRequestForHTTPGetAction(httpGet, container, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:8080", req.URL.Host)
+}
+
+func TestNewRequestsForHTTPGetAction(t *testing.T) {
+	httpGet := &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}
+	container := &v1.Container{Ports: []v1.ContainerPort{{ContainerPort: 8080}}}
+
+	testCases := []struct {
+		desc     string
+		host     string
+		podIPs   []string
+		expected []string
+	}{{
+		desc:     "v4-only pod",
+		podIPs:   []string{"10.0.0.1"},
+		expected: []string{"10.0.0.1:8080"},
+	}, {
+		desc:     "v6-only pod",
+		podIPs:   []string{"2001:db8::1"},
+		expected: []string{"[2001:db8::1]:8080"},
+	}, {
+		desc:     "dual-stack pod tries both families in order",
+		podIPs:   []string{"10.0.0.1", "2001:db8::1"},
+		expected: []string{"10.0.0.1:8080", "[2001:db8::1]:8080"},
+	}, {
+		desc:     "explicit Host pins the request and ignores podIPs",
+		host:     "example.com",
+		podIPs:   []string{"10.0.0.1", "2001:db8::1"},
+		expected: []string{"example.com:8080"},
+	}}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			get := *httpGet
+			get.Host = test.host
+
+			reqs, err := NewRequestsForHTTPGetAction(&get, container, test.podIPs)
+			require.NoError(t, err)
+
+			hosts := make([]string, 0, len(reqs))
+			for _, req := range reqs {
+				hosts = append(hosts, req.URL.Host)
+			}
+			assert.Equal(t, test.expected, hosts)
+		})
+	}
+}
+
+func TestVerifyAndNewRequestForHTTPGetAction(t *testing.T) {
+	httpGet := &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}
+	container := &v1.Container{Ports: []v1.ContainerPort{{ContainerPort: 8080}}}
+	raw := []byte("httpGet: {path: /healthz, port: 8080}")
+	keyring := trustedresources.Keyring{AnnotationPrefix: TrustedSourceAnnotationPrefix}
+
+	t.Run("matching digest is allowed", func(t *testing.T) {
+		sum := sha256.Sum256(raw)
+		source := &metav1.ObjectMeta{
+			Name:            "my-probe",
+			ResourceVersion: "1",
+			Annotations:     map[string]string{"probes.kubernetes.io/sha256": hex.EncodeToString(sum[:])},
+		}
+
+		req, err := VerifyAndNewRequestForHTTPGetAction(source, raw, keyring, httpGet, container, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.1:8080", req.URL.Host)
+	})
+
+	t.Run("tampered payload is rejected", func(t *testing.T) {
+		sum := sha256.Sum256(raw)
+		source := &metav1.ObjectMeta{
+			Name:            "my-probe",
+			ResourceVersion: "2",
+			Annotations:     map[string]string{"probes.kubernetes.io/sha256": hex.EncodeToString(sum[:])},
+		}
+
+		_, err := VerifyAndNewRequestForHTTPGetAction(source, []byte("httpGet: {path: /evil, port: 8080}"), keyring, httpGet, container, "10.0.0.1")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing digest annotation is rejected", func(t *testing.T) {
+		source := &metav1.ObjectMeta{Name: "my-probe", ResourceVersion: "3"}
+
+		_, err := VerifyAndNewRequestForHTTPGetAction(source, raw, keyring, httpGet, container, "10.0.0.1")
+		assert.Error(t, err)
+	})
+}
+
+func TestNewRequestsForHTTPGetActionNoPodIPs(t *testing.T) {
+	httpGet := &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}
+	container := &v1.Container{Ports: []v1.ContainerPort{{ContainerPort: 8080}}}
+
+	_, err := NewRequestsForHTTPGetAction(httpGet, container, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package term holds terminal-related types shared between the kubelet's streaming server and
+// the container runtimes it drives.
+package term
+
+// Size represents the width and height of a terminal, as reported by a client and plumbed down
+// to the container runtime to resize the process's pty.
+type Size struct {
+	Width  uint16
+	Height uint16
+}
@@ -17,91 +17,197 @@ limitations under the License.
 package ticker
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/runtime"
 )
 
-type Synchronizer struct {
-	tickerLock sync.RWMutex
-	tickers    map[time.Duration]*Ticker
+// granularity is the smallest tick interval the Synchronizer can schedule; every Ticker's period
+// must be a positive multiple of it.
+const granularity = time.Second
+
+// Clock abstracts time.Now for testability.
+type Clock interface {
+	Now() time.Time
 }
 
-// TODO: Make this a parameter to Synchronizer as necessary.
-const granularity = time.Second
+type realClock struct{}
 
-func (s *Synchronizer) NewTicker(period time.Duration) *Ticker {
-	if period%granularity != 0 {
-		// FIXME - what should the failure mode be?
-		glog.Fatalf("Period (%d) must be divisible by synchronizer granularity (%d)", period, granularity)
-	}
+func (realClock) Now() time.Time { return time.Now() }
 
-	c := make(chan Tick) // FIXME - maybe this should be buffered?
-	t := &Ticker{C: c, c: c}
+// Synchronizer multiplexes many independent Tickers onto a single periodic goroutine, so that
+// Tickers sharing a period fire in lock-step rather than drifting apart from each other over time.
+type Synchronizer struct {
+	lock    sync.Mutex
+	tickers map[time.Duration]*Ticker
 
-	s.tickerLock.Lock()
-	defer s.tickerLock.Unlock()
-	t.next = s.tickers[period]
-	s.tickers[period] = t
+	clock Clock
 }
 
-func (s *Synchronizer) Run() {
-	var count uint32
-	for _ = range time.Tick(granularity) {
-		count++
-		s.tick(count)
+// NewSynchronizer returns an empty, ready-to-use Synchronizer. Call Run to start ticking.
+func NewSynchronizer() *Synchronizer {
+	return &Synchronizer{
+		tickers: make(map[time.Duration]*Ticker),
+		clock:   realClock{},
 	}
 }
 
-func (s *Synchronizer) tick(count uint32) {
-	defer HandleCrash()
-	tickerLock.Lock()
-	defer tickerLock.Unlock()
+// roundPeriod rounds period up to the next positive multiple of granularity, returning an error
+// describing the rounding if one was needed.
+func roundPeriod(period time.Duration) (time.Duration, error) {
+	if period <= 0 {
+		return granularity, fmt.Errorf("period (%v) must be positive; rounded up to %v", period, granularity)
+	}
+	if rem := period % granularity; rem != 0 {
+		rounded := period + (granularity - rem)
+		return rounded, fmt.Errorf("period (%v) must be divisible by the synchronizer granularity (%v); rounded up to %v", period, granularity, rounded)
+	}
+	return period, nil
+}
 
-	for period := range s.tickers {
-		if count%period != 0 {
-			continue
-		}
+// NewTicker returns a Ticker that fires every period. bufferSize controls how many pending ticks
+// the returned Ticker's channel can hold; a slow receiver misses ticks once the buffer (0 meaning
+// unbuffered) is full, rather than blocking the Synchronizer.
+//
+// period must be a positive multiple of the Synchronizer's granularity (1 second); if it isn't,
+// NewTicker rounds it up to the next multiple and returns a non-nil error describing the
+// rounding. The returned Ticker is always usable, even when err is non-nil.
+func (s *Synchronizer) NewTicker(period time.Duration, bufferSize int) (*Ticker, error) {
+	rounded, err := roundPeriod(period)
+
+	c := make(chan Tick, bufferSize)
+	t := &Ticker{C: c, c: c, period: rounded, sync: s}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	t.next = s.tickers[rounded]
+	s.tickers[rounded] = t
+
+	return t, err
+}
 
-		// Find fist non-stopped ticker.
-		t := s.tickers[period]
-		for ; t != nil && t.stopped == stopped; t = t.next {
+// Run starts the Synchronizer's periodic goroutine, ticking at granularity until stopCh is
+// closed. It blocks for the lifetime of the Synchronizer; callers typically invoke it via `go`.
+func (s *Synchronizer) Run(stopCh <-chan struct{}) {
+	start := s.clock.Now()
+	underlying := time.NewTicker(granularity)
+	defer underlying.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-underlying.C:
+			// Derive the tick count from elapsed monotonic time, rather than incrementing a
+			// counter once per firing. That way, if a call to tick() overran its granularity
+			// budget and caused the underlying ticker to silently drop a firing, the next tick
+			// still lands on the correct absolute multiple of each ticker's period instead of
+			// permanently drifting by one granularity.
+			count := uint64(now.Sub(start) / granularity)
+			s.tick(count)
 		}
-		s.tickers[period] = t
-		for t != nil {
-			// Don't block if there is no receiver.
-			select {
-			case t.c <- Tick{}:
-			default:
-			}
+	}
+}
+
+// tick fires every Ticker whose period evenly divides count*granularity, and unlinks any Ticker
+// that has been stopped since the last tick.
+func (s *Synchronizer) tick(count uint64) {
+	defer runtime.HandleCrash()
 
-			// Find next non-stopped ticker.
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for period, head := range s.tickers {
+		periodTicks := uint64(period / granularity)
+		fire := count%periodTicks == 0
+
+		for t := head; t != nil; {
 			next := t.next
-			for ; next != nil && next.stopped == stopped; next = next.next {
+			if t.isStopped() {
+				// Unlink stopped tickers on every tick, in every bucket, rather than waiting for
+				// their period to fire, so Stop() is guaranteed to unlink its Ticker within one
+				// granularity.
+				s.unlinkLocked(t)
+			} else if fire {
+				select {
+				case t.c <- Tick{}:
+				default:
+					// Don't block the whole Synchronizer on a receiver that isn't ready.
+				}
 			}
-			t.next = next
 			t = next
 		}
 	}
 }
 
+// unlinkLocked removes t from the bucket for its current period. s.lock must be held.
+func (s *Synchronizer) unlinkLocked(t *Ticker) {
+	head := s.tickers[t.period]
+	if head == t {
+		s.tickers[t.period] = t.next
+		t.next = nil
+		return
+	}
+	for prev := head; prev != nil; prev = prev.next {
+		if prev.next == t {
+			prev.next = t.next
+			t.next = nil
+			return
+		}
+	}
+}
+
 const (
-	stopped = 1
+	tickerRunning = 0
+	tickerStopped = 1
 )
 
+// Ticker is a single subscriber of a Synchronizer. Its zero value is not usable; obtain one via
+// Synchronizer.NewTicker.
 type Ticker struct {
 	C <-chan Tick
 
 	// Same channel as C, used for sending Ticks.
 	c       chan Tick
 	stopped int32
-	next    *Ticker // Linked list
+	next    *Ticker // Intrusive singly-linked list within a period bucket; guarded by sync.lock.
+
+	period time.Duration
+	sync   *Synchronizer
 }
 
+func (t *Ticker) isStopped() bool {
+	return atomic.LoadInt32(&t.stopped) == tickerStopped
+}
+
+// Stop stops the Ticker. It is unlinked from its Synchronizer within one granularity of the call
+// to Stop; no further Ticks are sent afterward. Stop is idempotent and safe to call
+// concurrently with the Synchronizer's Run goroutine.
 func (t *Ticker) Stop() {
-	atomic.StoreInt32(&t.stopped, stopped)
+	atomic.StoreInt32(&t.stopped, tickerStopped)
+}
+
+// Reset changes the period this Ticker fires on, taking effect within one granularity tick. Like
+// NewTicker, a period that isn't a positive multiple of the granularity is rounded up, and the
+// rounding (if any) is returned as a non-nil error.
+func (t *Ticker) Reset(period time.Duration) error {
+	rounded, err := roundPeriod(period)
+
+	t.sync.lock.Lock()
+	defer t.sync.lock.Unlock()
+
+	t.sync.unlinkLocked(t)
+	atomic.StoreInt32(&t.stopped, tickerRunning)
+	t.period = rounded
+	t.next = t.sync.tickers[rounded]
+	t.sync.tickers[rounded] = t
+
+	return err
 }
 
+// Tick is sent on a Ticker's channel each time it fires. It carries no data; it exists so the
+// channel's element type can't be confused with another empty-struct channel.
 type Tick struct{}
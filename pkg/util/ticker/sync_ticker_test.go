@@ -0,0 +1,141 @@
+/*
+Copyright 2015 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ticker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain(t *testing.T, c <-chan Tick) int {
+	t.Helper()
+	n := 0
+	for {
+		select {
+		case <-c:
+			n++
+		default:
+			return n
+		}
+	}
+}
+
+func TestNewTickerRoundsPeriod(t *testing.T) {
+	s := NewSynchronizer()
+
+	ticker, err := s.NewTicker(granularity, 1)
+	assert.NoError(t, err, "an exact multiple of the granularity shouldn't need rounding")
+	assert.Equal(t, granularity, ticker.period)
+
+	ticker, err = s.NewTicker(granularity+time.Millisecond, 1)
+	assert.Error(t, err, "a period that isn't a multiple of the granularity should be reported as rounded")
+	assert.Equal(t, 2*granularity, ticker.period, "the period should be rounded up, not down")
+
+	ticker, err = s.NewTicker(0, 1)
+	assert.Error(t, err, "a non-positive period should be reported as rounded")
+	assert.Equal(t, granularity, ticker.period)
+}
+
+func TestTickSharedPeriod(t *testing.T) {
+	s := NewSynchronizer()
+
+	a, err := s.NewTicker(2*granularity, 1)
+	require.NoError(t, err)
+	b, err := s.NewTicker(2*granularity, 1)
+	require.NoError(t, err)
+	other, err := s.NewTicker(3*granularity, 1)
+	require.NoError(t, err)
+
+	s.tick(1)
+	assert.Zero(t, drain(t, a.C), "ticker shouldn't fire before its period elapses")
+	assert.Zero(t, drain(t, b.C))
+	assert.Zero(t, drain(t, other.C))
+
+	s.tick(2)
+	assert.Equal(t, 1, drain(t, a.C), "tickers sharing a period should fire together")
+	assert.Equal(t, 1, drain(t, b.C))
+	assert.Zero(t, drain(t, other.C))
+
+	s.tick(3)
+	assert.Zero(t, drain(t, a.C))
+	assert.Zero(t, drain(t, b.C))
+	assert.Equal(t, 1, drain(t, other.C))
+}
+
+func TestTickSurvivesMissedTick(t *testing.T) {
+	// Simulates a GC pause (or any other overrun) causing the underlying ticker to silently drop
+	// a firing: count jumps from 2 straight to 4, skipping 3. A ticker with a period of 2
+	// granularities should still fire on every even count it's actually given, regardless of the
+	// gap.
+	s := NewSynchronizer()
+	ticker, err := s.NewTicker(2*granularity, 1)
+	require.NoError(t, err)
+
+	s.tick(2)
+	assert.Equal(t, 1, drain(t, ticker.C))
+
+	s.tick(4) // count 3 was dropped by the runtime, not by the Synchronizer.
+	assert.Equal(t, 1, drain(t, ticker.C), "the ticker should still fire on the next even count it sees")
+}
+
+func TestTickerStopUnlinksWithinOneTick(t *testing.T) {
+	s := NewSynchronizer()
+	ticker, err := s.NewTicker(granularity, 1)
+	require.NoError(t, err)
+
+	ticker.Stop()
+	s.tick(1)
+
+	assert.Zero(t, drain(t, ticker.C), "a stopped ticker should never fire")
+	assert.Nil(t, s.tickers[granularity], "a stopped ticker should be unlinked within one tick")
+}
+
+func TestTickerReset(t *testing.T) {
+	s := NewSynchronizer()
+	ticker, err := s.NewTicker(granularity, 1)
+	require.NoError(t, err)
+
+	err = ticker.Reset(2 * granularity)
+	assert.NoError(t, err)
+
+	s.tick(1)
+	assert.Zero(t, drain(t, ticker.C), "the ticker shouldn't fire on its old period anymore")
+
+	s.tick(2)
+	assert.Equal(t, 1, drain(t, ticker.C), "the ticker should fire on its new period")
+}
+
+func TestTickerBufferDropsWhenFull(t *testing.T) {
+	s := NewSynchronizer()
+	ticker, err := s.NewTicker(granularity, 0) // unbuffered
+	require.NoError(t, err)
+
+	// With no receiver, an unbuffered ticker's send must not block the Synchronizer.
+	done := make(chan struct{})
+	go func() {
+		s.tick(1)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("tick() blocked on a ticker with no receiver")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trustedresources verifies that a resource loaded from an external source (a ConfigMap
+// or CRD holding a probe spec or a policy bundle, say) matches the payload its author published,
+// before kubelet or kube-apiserver acts on it. A SHA-256 digest recorded in an annotation on the
+// resource is always checked against the raw payload; when the caller additionally configures a
+// Keyring, a detached signature annotation is checked against it too.
+package trustedresources
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// sha256AnnotationSuffix names the annotation holding the expected hex-encoded SHA-256 digest
+	// of the resource's raw payload, e.g. "probes.kubernetes.io/sha256".
+	sha256AnnotationSuffix = "sha256"
+	// signatureAnnotationSuffix names the annotation holding a base64-encoded detached signature
+	// over the raw payload's SHA-256 digest, e.g. "probes.kubernetes.io/cosign-signature".
+	signatureAnnotationSuffix = "cosign-signature"
+)
+
+// Keyring configures Verify for one class of trusted resource: which annotations to read, and,
+// if signature verification is required, which public keys a detached signature may validate
+// against. A signature that validates against any key in Keys is accepted, so a key can be
+// rotated in before the old one is rotated out.
+type Keyring struct {
+	// AnnotationPrefix is prepended to "sha256" and "cosign-signature" to read this keyring's
+	// annotations off a resource, e.g. "probes.kubernetes.io" or "policy.kubernetes.io".
+	AnnotationPrefix string
+	// Keys are the public keys a detached signature is checked against. A nil or empty Keys
+	// disables signature verification: only the digest annotation is checked.
+	Keys []*ecdsa.PublicKey
+}
+
+func (k Keyring) sha256Annotation() string    { return k.AnnotationPrefix + "/" + sha256AnnotationSuffix }
+func (k Keyring) signatureAnnotation() string { return k.AnnotationPrefix + "/" + signatureAnnotationSuffix }
+
+// cacheKey identifies one verification result: a specific object at a specific resourceVersion,
+// checked against a specific keyring. resourceVersion alone isn't globally unique, so it's paired
+// with the object's UID; the keyring is part of the key so that retiring a key takes effect.
+type cacheKey struct {
+	uid             types.UID
+	resourceVersion string
+	keyring         string
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[cacheKey]error{}
+)
+
+// Verify checks raw against the digest and, if keyring.Keys is non-empty, the signature recorded
+// in obj's annotations (see Keyring). Results are cached by obj's (UID, resourceVersion) and the
+// keyring's annotation prefix and set of keys, so repeated calls for the same object version -- the common case on the probe and admission hot
+// paths, where the same ConfigMap or CRD is re-read on every reconcile -- don't re-hash or
+// re-verify. Objects with no resourceVersion (not yet persisted) are never cached.
+func Verify(obj metav1.Object, raw []byte, keyring Keyring) error {
+	if obj == nil {
+		return fmt.Errorf("trustedresources: cannot verify a nil object")
+	}
+	keyringID := keyring.AnnotationPrefix
+	for _, k := range keyring.Keys {
+		keyringID += fmt.Sprintf("|%p", k)
+	}
+	key := cacheKey{uid: obj.GetUID(), resourceVersion: obj.GetResourceVersion(), keyring: keyringID}
+	if key.resourceVersion != "" {
+		cacheMu.Lock()
+		err, ok := cache[key]
+		cacheMu.Unlock()
+		if ok {
+			return err
+		}
+	}
+
+	err := verify(obj, raw, keyring)
+
+	if key.resourceVersion != "" {
+		cacheMu.Lock()
+		cache[key] = err
+		cacheMu.Unlock()
+	}
+	return err
+}
+
+func verify(obj metav1.Object, raw []byte, keyring Keyring) error {
+	annotations := obj.GetAnnotations()
+	wantDigest := annotations[keyring.sha256Annotation()]
+	if wantDigest == "" {
+		return fmt.Errorf("trustedresources: missing %s annotation", keyring.sha256Annotation())
+	}
+	sum := sha256.Sum256(raw)
+	gotDigest := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(gotDigest), []byte(wantDigest)) != 1 {
+		return fmt.Errorf("trustedresources: payload does not match %s annotation", keyring.sha256Annotation())
+	}
+
+	if len(keyring.Keys) == 0 {
+		return nil
+	}
+
+	sigAnnotation := keyring.signatureAnnotation()
+	encodedSig := annotations[sigAnnotation]
+	if encodedSig == "" {
+		return fmt.Errorf("trustedresources: keyring configured but %s annotation is missing", sigAnnotation)
+	}
+	sig, err := base64.StdEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return fmt.Errorf("trustedresources: invalid %s annotation: %v", sigAnnotation, err)
+	}
+	for _, key := range keyring.Keys {
+		if key != nil && ecdsa.VerifyASN1(key, sum[:], sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("trustedresources: signature in %s does not verify against any key in the configured keyring", sigAnnotation)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trustedresources
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func signedConfigMap(t *testing.T, raw []byte, resourceVersion string, key *ecdsa.PrivateKey) *metav1.ObjectMeta {
+	t.Helper()
+	sum := sha256.Sum256(raw)
+	annotations := map[string]string{
+		"probes.kubernetes.io/sha256": hex.EncodeToString(sum[:]),
+	}
+	if key != nil {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+		require.NoError(t, err)
+		annotations["probes.kubernetes.io/cosign-signature"] = base64.StdEncoding.EncodeToString(sig)
+	}
+	return &metav1.ObjectMeta{
+		Name:            "my-probe",
+		ResourceVersion: resourceVersion,
+		Annotations:     annotations,
+	}
+}
+
+func TestVerify_DigestOnly(t *testing.T) {
+	raw := []byte("probe spec v1")
+	keyring := Keyring{AnnotationPrefix: "probes.kubernetes.io"}
+
+	t.Run("matching digest is allowed", func(t *testing.T) {
+		obj := signedConfigMap(t, raw, "1", nil)
+		assert.NoError(t, Verify(obj, raw, keyring))
+	})
+
+	t.Run("tampered payload is rejected", func(t *testing.T) {
+		obj := signedConfigMap(t, raw, "2", nil)
+		assert.Error(t, Verify(obj, []byte("probe spec v2"), keyring))
+	})
+
+	t.Run("missing digest annotation is rejected", func(t *testing.T) {
+		obj := &metav1.ObjectMeta{Name: "my-probe", ResourceVersion: "3"}
+		assert.Error(t, Verify(obj, raw, keyring))
+	})
+}
+
+func TestVerify_Signature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw := []byte("policy bundle v1")
+	keyring := Keyring{AnnotationPrefix: "probes.kubernetes.io", Keys: []*ecdsa.PublicKey{&key.PublicKey}}
+
+	t.Run("valid signature is allowed", func(t *testing.T) {
+		obj := signedConfigMap(t, raw, "1", key)
+		assert.NoError(t, Verify(obj, raw, keyring))
+	})
+
+	t.Run("signature from untrusted key is rejected", func(t *testing.T) {
+		obj := signedConfigMap(t, raw, "2", otherKey)
+		assert.Error(t, Verify(obj, raw, keyring))
+	})
+
+	t.Run("missing signature annotation is rejected when a keyring is configured", func(t *testing.T) {
+		obj := signedConfigMap(t, raw, "3", nil)
+		assert.Error(t, Verify(obj, raw, keyring))
+	})
+
+	t.Run("key rotation: old and new key both verify until the old one is retired", func(t *testing.T) {
+		rotatingKeyring := Keyring{AnnotationPrefix: "probes.kubernetes.io", Keys: []*ecdsa.PublicKey{&key.PublicKey, &otherKey.PublicKey}}
+		objSignedByOld := signedConfigMap(t, raw, "4", key)
+		objSignedByNew := signedConfigMap(t, raw, "5", otherKey)
+		assert.NoError(t, Verify(objSignedByOld, raw, rotatingKeyring))
+		assert.NoError(t, Verify(objSignedByNew, raw, rotatingKeyring))
+
+		retiredKeyring := Keyring{AnnotationPrefix: "probes.kubernetes.io", Keys: []*ecdsa.PublicKey{&otherKey.PublicKey}}
+		assert.Error(t, Verify(objSignedByOld, raw, retiredKeyring))
+	})
+}
+
+func TestVerify_CachesByResourceVersion(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw := []byte("probe spec v1")
+	keyring := Keyring{AnnotationPrefix: "probes.kubernetes.io", Keys: []*ecdsa.PublicKey{&key.PublicKey}}
+	obj := signedConfigMap(t, raw, "cached-1", key)
+
+	require.NoError(t, Verify(obj, raw, keyring))
+
+	// Mutating raw after the first, cached call shouldn't change the result for the same
+	// (UID, resourceVersion): the hot path trusts the cache rather than re-hashing every call.
+	assert.NoError(t, Verify(obj, []byte("tampered but not re-checked"), keyring))
+
+	// A new resourceVersion for the same tampered payload is not cached, so it is re-verified and
+	// rejected.
+	obj.ResourceVersion = "cached-2"
+	assert.Error(t, Verify(obj, []byte("tampered but not re-checked"), keyring))
+}
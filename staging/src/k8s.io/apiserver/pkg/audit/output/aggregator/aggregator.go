@@ -14,9 +14,15 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package aggregator implements an audit.Backend that buffers the per-stage events of a single
+// request (RequestReceived, ResponseStarted, ResponseComplete/Panic) and flushes a single,
+// merged event to a delegate backend once the request's final stage arrives (or the entry ages
+// out of the cache, whichever comes first).
 package aggregator
 
 import (
+	"container/list"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -25,172 +31,267 @@ import (
 	"k8s.io/apiserver/pkg/apis/audit"
 )
 
-// FIXME - REMOVE THIS vvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvvv
-
+// Sink is the minimal interface needed to deliver aggregated events onward.
 type Sink interface {
 	ProcessEvents(events ...*audit.Event)
 }
 
+// Backend is a Sink that can be run as part of the audit pipeline's lifecycle.
 type Backend interface {
 	Sink
 
 	Run(stopCh <-chan struct{}) error
 }
 
-// FIXME - REMOVE THIS ^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^
+// Clock abstracts time.Now for testability.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// terminalStages are the stages that mark a request's audit trail as complete and ready to be
+// flushed, even if later stages (which shouldn't occur) arrive afterward.
+var terminalStages = map[audit.Stage]bool{
+	audit.StageResponseComplete: true,
+	audit.StagePanic:            true,
+}
+
+// stageRank orders stages by how far through a request's lifecycle they represent, so the
+// aggregated event's Stage can be taken as the highest one observed rather than the one that
+// happened to arrive last. Panic ranks above ResponseComplete: a panic can be recorded after the
+// response is already considered complete, and should still win.
+var stageRank = map[audit.Stage]int{
+	audit.StageRequestReceived:  0,
+	audit.StageResponseStarted:  1,
+	audit.StageResponseComplete: 2,
+	audit.StagePanic:            3,
+}
 
 type aggregator struct {
 	// The maximum amount of time to hold an event in cache before it is flushed to the delegate
-	// backend.
+	// backend, complete or not.
 	ttl   time.Duration
 	clock Clock
 
-	buffer          chan *audit.Event
-	cache           map[types.UID]*cacheEntry
-	cacheHead       *cacheEntry
-	cacheTail       *cacheEntry
-	expirationTimer time.Timer
+	buffer chan *audit.Event
+
+	// cacheSize is the maximum number of in-flight entries to hold before the oldest (by
+	// first-seen time) are force-flushed to make room.
+	cacheSize int
+
+	// cache and lru are only ever touched from the run() goroutine, so no locking is needed.
+	cache map[types.UID]*list.Element
+	lru   *list.List // of *cacheEntry, ordered oldest (front) to newest (back) by first-seen time
+
+	expirationTimer *time.Timer
 
 	delegate Backend
+
+	// droppedEvents counts events discarded because the buffer was full; it's incremented from
+	// whichever goroutine calls ProcessEvents, so it must be accessed atomically.
+	droppedEvents int64
+	// expiredIncomplete counts cache entries flushed by expire() that never saw a terminal
+	// stage. It's only ever incremented from the run() goroutine, but is read atomically since
+	// ExpiredIncomplete may be called from elsewhere (e.g. a metrics scrape).
+	expiredIncomplete int64
 }
 
-// TODO: make these parameters
+// Default tuning parameters for NewAggregatorBackend.
 const (
-	BufferSize = 100
-	CacheSize  = 1000
-	TTL        = 5 * time.Second
+	DefaultBufferSize = 100
+	DefaultCacheSize  = 10000
+	DefaultTTL        = 5 * time.Second
 )
 
-func NewAggregatorBackend(delegate Backend) Backend {
+// NewAggregatorBackend wraps delegate with stage-aware aggregation: events for the same request
+// (grouped by AuditID) are buffered until the terminal stage arrives, then merged into a single
+// event and forwarded to delegate. bufferSize bounds the channel used to hand events from
+// ProcessEvents to the aggregating goroutine. cacheSize bounds the number of in-flight entries
+// held at once; once exceeded, the oldest entry is force-flushed to make room. ttl bounds how
+// long an incomplete entry is held before it's flushed regardless.
+func NewAggregatorBackend(delegate Backend, bufferSize, cacheSize int, ttl time.Duration) Backend {
 	return &aggregator{
-		buffer:   make(chan *audit.Event, BufferSize),
-		cache:    make(map[string]*cacheEntry),
-		delegate: delegate,
+		ttl:       ttl,
+		clock:     realClock{},
+		buffer:    make(chan *audit.Event, bufferSize),
+		cacheSize: cacheSize,
+		cache:     make(map[types.UID]*list.Element),
+		lru:       list.New(),
+		delegate:  delegate,
 	}
 }
 
 func (a *aggregator) ProcessEvents(events ...*audit.Event) {
 	for _, ev := range events {
-		buffer <- ev
+		select {
+		case a.buffer <- ev:
+		default:
+			atomic.AddInt64(&a.droppedEvents, 1)
+			glog.V(2).Infof("Dropping audit event %s: aggregator buffer full", ev.AuditID)
+		}
 	}
 }
 
+// DroppedEvents returns the number of events discarded so far because the buffer was full.
+func (a *aggregator) DroppedEvents() int64 {
+	return atomic.LoadInt64(&a.droppedEvents)
+}
+
+// ExpiredIncomplete returns the number of cache entries flushed so far by expire() without ever
+// having seen a terminal stage.
+func (a *aggregator) ExpiredIncomplete() int64 {
+	return atomic.LoadInt64(&a.expiredIncomplete)
+}
+
 func (a *aggregator) Run(stopCh <-chan struct{}) error {
-	delegate.Run(stopCh)
+	if err := a.delegate.Run(stopCh); err != nil {
+		return err
+	}
 
 	go a.run(stopCh)
 
 	return nil
 }
 
-func (a *aggregator) run(stopCh <-chan struct{}) error {
-	a.expirationTimer = time.Timer(a.ttl)
+func (a *aggregator) run(stopCh <-chan struct{}) {
+	a.expirationTimer = time.NewTimer(a.ttl)
+	defer a.expirationTimer.Stop()
+
 	for {
 		select {
 		case <-stopCh:
 			glog.V(2).Infof("Received stop: shutting down aggregator audit backend")
+			a.flushAll()
 			return
-		case ev := <-buffer:
-			a.injest(ev)
-		case <-a.expirationTimer:
+		case ev := <-a.buffer:
+			a.ingest(ev)
+		case <-a.expirationTimer.C:
 			a.expire()
 		}
 	}
 }
 
-func (a *aggregator) injest(ev *audit.Event) {
-	if entry, ok := a.cache[ev.AuditID]; ok {
+// ingest adds ev to the cache entry for its AuditID, creating one if necessary, and flushes the
+// entry immediately if ev's stage is terminal.
+func (a *aggregator) ingest(ev *audit.Event) {
+	if elem, ok := a.cache[ev.AuditID]; ok {
+		entry := elem.Value.(*cacheEntry)
 		entry.events = append(entry.events, ev)
-		if entry.complete() {
-			a.send(entry)
+		if terminalStages[ev.Stage] {
+			a.flush(elem)
 		}
 		return
 	}
-	entry = &cacheEntry{events: []*audit.Event{ev}}
-	if entry.Complete() {
-		a.send(entry)
-	} else {
-		a.insert(entry)
-	}
-}
 
-func (a *aggregator) insert(entry *cacheEntry) {
-	cache[entry.id()] = entry
-	if a.cacheHead == nil {
-		a.cacheHead = entry
-		a.cacheTail = entry
+	entry := &cacheEntry{events: []*audit.Event{ev}}
+	if terminalStages[ev.Stage] {
+		a.delegate.ProcessEvents(entry.aggregate())
 		return
 	}
+	elem := a.lru.PushBack(entry)
+	a.cache[ev.AuditID] = elem
 
-	// Assume events come in roughly the right order.
-	for pos := a.cacheTail; pos != nil; pos = pos.prev {
-		if pos.timestamp().Before(entry.timestamp()) {
-			entry.next = pos.next
-			pos.next = entry
-			entry.prev = pos
-			if entry.next != nil {
-				entry.next.prev = entry
-			} else {
-				a.cacheTail = entry
-			}
-			return
-		}
-	}
-	entry.next = a.cacheHead
-	a.cacheHead = entry
-	if entry.next != nil {
-		entry.next.prev = entry
+	if a.cacheSize > 0 && len(a.cache) > a.cacheSize {
+		a.flush(a.lru.Front())
 	}
 }
 
-func (a *aggregator) send(entry *cacheEntry) {
-	// Remove entry from cache.
+// flush removes elem from the cache and sends its aggregated event to the delegate.
+func (a *aggregator) flush(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
 	delete(a.cache, entry.id())
-	if entry.prev != nil {
-		entry.prev.next = entry.next
-	}
-	if entry.next != nil {
-		entry.next.prev = entry.prev
-	}
-	if a.cacheHead == entry {
-		a.cacheHead = entry.next
-	}
-	if a.cacheTail == entry {
-		a.cacheTail = entry.prev
-	}
+	a.lru.Remove(elem)
 
 	a.delegate.ProcessEvents(entry.aggregate())
 }
 
+// flushAll drains every in-flight entry, in case the backend is shut down before a request's
+// final stage arrives.
+func (a *aggregator) flushAll() {
+	for elem := a.lru.Front(); elem != nil; {
+		next := elem.Next()
+		a.flush(elem)
+		elem = next
+	}
+}
+
+// expire flushes every entry that has aged out of the TTL window, and resets the expiration
+// timer to fire when the next-oldest entry would expire.
 func (a *aggregator) expire() {
-	for a.cacheHead != nil && a.cacheHead.timestamp().Add(a.ttl).Before(a.clock.Now()) {
-		a.send(a.cacheHead)
+	now := a.clock.Now()
+	for elem := a.lru.Front(); elem != nil; elem = a.lru.Front() {
+		entry := elem.Value.(*cacheEntry)
+		if entry.timestamp().Add(a.ttl).After(now) {
+			break
+		}
+		if !entry.sawTerminalStage() {
+			atomic.AddInt64(&a.expiredIncomplete, 1)
+		}
+		a.flush(elem)
 	}
-	// Reset expiration timer.
-	if a.cacheHead != nil {
-		a.expirationTimer.Reset(a.cacheHead.timestamp().Add(a.ttl).Sub(a.clock.Now()))
+
+	if elem := a.lru.Front(); elem != nil {
+		entry := elem.Value.(*cacheEntry)
+		a.expirationTimer.Reset(entry.timestamp().Add(a.ttl).Sub(now))
 	} else {
 		a.expirationTimer.Reset(a.ttl)
 	}
 }
 
+// cacheEntry buffers the per-stage events seen so far for a single request.
 type cacheEntry struct {
-	prev, next *cacheEntry
-	events     []*audit.Event
+	events []*audit.Event
 }
 
+// aggregate merges the buffered per-stage events into a single event: request-time fields (verb,
+// user, object ref, request object, ...) come from the first stage seen; response-time fields
+// (response status, response object) come from whichever stage first populated them, since a
+// later stage re-reporting the same field shouldn't overwrite it; annotations are merged across
+// all stages; and Stage/StageTimestamp are taken from the highest stage observed, so an
+// out-of-order arrival (e.g. RequestReceived arriving after ResponseStarted) doesn't regress them.
 func (e *cacheEntry) aggregate() *audit.Event {
-	// TODO - intelligently combine if information is missing
-	return e.events[len(e.events)-1] // FIXME - return the final stage
+	merged := *e.events[0] // shallow copy: request-time fields come from the first stage.
+
+	for _, ev := range e.events[1:] {
+		if merged.ResponseStatus == nil && ev.ResponseStatus != nil {
+			merged.ResponseStatus = ev.ResponseStatus
+		}
+		if merged.ResponseObject == nil && ev.ResponseObject != nil {
+			merged.ResponseObject = ev.ResponseObject
+		}
+		if len(ev.Annotations) > 0 {
+			if merged.Annotations == nil {
+				merged.Annotations = make(map[string]string, len(ev.Annotations))
+			}
+			for k, v := range ev.Annotations {
+				merged.Annotations[k] = v
+			}
+		}
+		if stageRank[ev.Stage] > stageRank[merged.Stage] {
+			merged.Stage = ev.Stage
+			merged.StageTimestamp = ev.StageTimestamp
+		}
+	}
+
+	return &merged
 }
 
-func (e *cacheEntry) complete() bool {
-	// FIXME - event is complete when it receives the final stage
-	return len(events) > 1
+// sawTerminalStage reports whether any buffered event reached a terminal stage, i.e. whether this
+// entry represents a complete (rather than merely timed-out) request trail.
+func (e *cacheEntry) sawTerminalStage() bool {
+	for _, ev := range e.events {
+		if terminalStages[ev.Stage] {
+			return true
+		}
+	}
+	return false
 }
 
 func (e *cacheEntry) timestamp() time.Time {
-	return e.events[0].Timestamp.Time
+	return e.events[0].StageTimestamp.Time
 }
 
 func (e *cacheEntry) id() types.UID {
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregator
+
+import (
+	"container/list"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+// fakeClock lets tests control what a.clock.Now() returns without sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// fakeSink records every event handed to ProcessEvents, in the order received.
+type fakeSink struct {
+	events []*audit.Event
+}
+
+func (s *fakeSink) ProcessEvents(events ...*audit.Event) {
+	s.events = append(s.events, events...)
+}
+
+func newTestAggregator(ttl time.Duration) (*aggregator, *fakeClock, *fakeSink) {
+	clock := &fakeClock{now: time.Now()}
+	sink := &fakeSink{}
+	a := &aggregator{
+		ttl:       ttl,
+		clock:     clock,
+		buffer:    make(chan *audit.Event, DefaultBufferSize),
+		cacheSize: DefaultCacheSize,
+		cache:     make(map[types.UID]*list.Element),
+		lru:       list.New(),
+		delegate:  &fakeBackend{Sink: sink},
+		// run() normally creates the timer; expire() resets it.
+		expirationTimer: time.NewTimer(ttl),
+	}
+	return a, clock, sink
+}
+
+// fakeBackend adapts a Sink into a Backend for tests that never call Run.
+type fakeBackend struct {
+	Sink
+}
+
+func (fakeBackend) Run(stopCh <-chan struct{}) error { return nil }
+
+func event(auditID types.UID, stage audit.Stage, at time.Time) *audit.Event {
+	return &audit.Event{
+		AuditID:        auditID,
+		Stage:          stage,
+		StageTimestamp: metav1.NewMicroTime(at),
+	}
+}
+
+func TestAggregatorOutOfOrderStageArrival(t *testing.T) {
+	a, clock, sink := newTestAggregator(time.Minute)
+
+	// ResponseStarted arrives before RequestReceived, simulating network reordering between the
+	// two calls to ProcessEvents. The merged Stage must still end up at the highest stage seen
+	// (ResponseComplete), not whichever stage happened to arrive last.
+	a.ingest(event("req-1", audit.StageResponseStarted, clock.now))
+	a.ingest(event("req-1", audit.StageRequestReceived, clock.now))
+	a.ingest(event("req-1", audit.StageResponseComplete, clock.now))
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.StageResponseComplete, sink.events[0].Stage)
+}
+
+func TestAggregatorMissingFinalStage(t *testing.T) {
+	a, clock, sink := newTestAggregator(time.Minute)
+
+	a.ingest(event("req-1", audit.StageRequestReceived, clock.now))
+	a.ingest(event("req-1", audit.StageResponseStarted, clock.now))
+	assert.Empty(t, sink.events, "entry should not flush before its final stage arrives or it expires")
+
+	// Age the entry past the TTL without ever sending ResponseComplete/Panic.
+	clock.now = clock.now.Add(2 * time.Minute)
+	a.expire()
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.StageResponseStarted, sink.events[0].Stage)
+	assert.EqualValues(t, 1, a.ExpiredIncomplete())
+}
+
+func TestAggregatorPanicShortCircuitsResponseComplete(t *testing.T) {
+	a, clock, sink := newTestAggregator(time.Minute)
+
+	a.ingest(event("req-1", audit.StageRequestReceived, clock.now))
+	a.ingest(event("req-1", audit.StageResponseStarted, clock.now))
+	a.ingest(event("req-1", audit.StagePanic, clock.now))
+
+	// Panic is terminal, so the entry is flushed immediately without waiting for
+	// ResponseComplete or the TTL, and Stage reflects Panic, which is reported in place of the
+	// ResponseComplete that never arrived.
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, audit.StagePanic, sink.events[0].Stage)
+	assert.Zero(t, a.ExpiredIncomplete())
+}
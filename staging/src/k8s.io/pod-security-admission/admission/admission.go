@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission wires the policy package's checks into an admission plugin: it extracts the
+// pod template (metadata and PodSpec) embedded in an incoming object, evaluates it against the
+// namespace's configured PodSecurity level, and turns the result into an admission decision.
+package admission
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PodSpecExtractor extracts a pod template's metadata and PodSpec from a runtime.Object, so the
+// admission plugin can evaluate policy checks against it without special-casing every kind that
+// embeds a pod template.
+type PodSpecExtractor interface {
+	// HasPodSpec returns whether gr is a kind this extractor knows how to extract a PodSpec from.
+	HasPodSpec(gr schema.GroupResource) bool
+	// ExtractPodSpec returns obj's embedded pod template metadata and PodSpec, or an error if obj
+	// has none.
+	ExtractPodSpec(obj runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, error)
+}
+
+// DefaultPodSpecExtractor knows how to extract the PodSpec from the built-in kinds that embed a
+// pod template: Pod, PodTemplate, ReplicationController, ReplicaSet, Deployment, StatefulSet,
+// DaemonSet, Job, and CronJob.
+type DefaultPodSpecExtractor struct{}
+
+var _ PodSpecExtractor = &DefaultPodSpecExtractor{}
+
+var defaultPodSpecResources = map[schema.GroupResource]bool{
+	corev1.Resource("pods"):                   true,
+	corev1.Resource("replicationcontrollers"): true,
+	corev1.Resource("podtemplates"):           true,
+	appsv1.Resource("replicasets"):            true,
+	appsv1.Resource("deployments"):            true,
+	appsv1.Resource("statefulsets"):           true,
+	appsv1.Resource("daemonsets"):             true,
+	batchv1.Resource("jobs"):                  true,
+	batchv1.Resource("cronjobs"):              true,
+}
+
+// HasPodSpec returns whether gr is one of the built-in kinds DefaultPodSpecExtractor knows how to
+// extract a PodSpec from.
+func (d *DefaultPodSpecExtractor) HasPodSpec(gr schema.GroupResource) bool {
+	return defaultPodSpecResources[gr]
+}
+
+// ExtractPodSpec returns obj's embedded pod template metadata and PodSpec. obj must be one of the
+// built-in kinds HasPodSpec recognizes; any other type is an error.
+func (d *DefaultPodSpecExtractor) ExtractPodSpec(obj runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, error) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		return &o.ObjectMeta, &o.Spec, nil
+	case *corev1.PodTemplate:
+		return &o.Template.ObjectMeta, &o.Template.Spec, nil
+	case *corev1.ReplicationController:
+		if o.Spec.Template == nil {
+			return nil, nil, fmt.Errorf("replicationcontroller %s has no pod template", o.Name)
+		}
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *appsv1.Deployment:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *batchv1.Job:
+		return &o.Spec.Template.ObjectMeta, &o.Spec.Template.Spec, nil
+	case *batchv1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.ObjectMeta, &o.Spec.JobTemplate.Spec.Template.Spec, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected object type: %T", obj)
+	}
+}
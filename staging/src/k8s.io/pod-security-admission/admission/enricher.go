@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/pod-security-admission/policy"
+)
+
+// Enricher runs after ExtractPodSpec and before policy evaluation, decorating a
+// policy.EvaluationContext with cluster metadata (namespace labels, owner-chain runtimeClass,
+// image-signing attestations, node-selector projections, ...) that checks implementing
+// policy.ContextCheck can consult, so individual checks don't each need to re-fetch it.
+type Enricher interface {
+	// Enrich augments evalContext with whatever data this Enricher contributes. An error means
+	// this Enricher's contribution is unavailable for this request; it does not fail admission.
+	Enrich(ctx context.Context, req *admissionv1.AdmissionRequest, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, evalContext *policy.EvaluationContext) error
+}
+
+// CompositeEnricher runs Enrichers in sequence against a shared policy.EvaluationContext,
+// passing each a context bounded by PerEnricherTimeout. Each Enrich call is synchronous: the
+// timeout reaches an enricher only through its ctx, and an enricher that does not honor ctx (a
+// lister cache miss falling through to a live GET, a remote attestation lookup) is not
+// interrupted. An enricher error is logged and skipped; enrichment never fails the admission
+// request.
+type CompositeEnricher struct {
+	Enrichers []Enricher
+	// PerEnricherTimeout bounds each Enricher's Enrich call. Zero means no timeout.
+	PerEnricherTimeout time.Duration
+}
+
+var _ Enricher = &CompositeEnricher{}
+
+// Enrich runs every configured Enricher in order, each adding its contribution to evalContext.
+// It always returns a nil error: a failing or slow enricher is logged
+// and skipped rather than propagated, since enrichment is advisory.
+func (c *CompositeEnricher) Enrich(ctx context.Context, req *admissionv1.AdmissionRequest, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, evalContext *policy.EvaluationContext) error {
+	for _, e := range c.Enrichers {
+		enrichCtx := ctx
+		cancel := func() {}
+		if c.PerEnricherTimeout > 0 {
+			enrichCtx, cancel = context.WithTimeout(ctx, c.PerEnricherTimeout)
+		}
+		err := e.Enrich(enrichCtx, req, podMetadata, podSpec, evalContext)
+		cancel()
+		if err != nil {
+			klog.V(4).InfoS("admission enricher failed, continuing without its contribution", "namespace", req.Namespace, "name", req.Name, "err", err)
+		}
+	}
+	return nil
+}
+
+// NamespaceLabelEnricher is the default Enricher: it attaches the labels of the pod's namespace,
+// read from a shared informer's lister cache, into EvaluationContext.NamespaceLabels. This lets
+// checks express rules keyed on namespace metadata (e.g. "namespaces labeled tier=prod may not run
+// privileged pods regardless of enforce level") without every check, or every request, hitting
+// the API server for the namespace.
+type NamespaceLabelEnricher struct {
+	NamespaceLister corev1listers.NamespaceLister
+}
+
+var _ Enricher = &NamespaceLabelEnricher{}
+
+// NewNamespaceLabelEnricher returns a NamespaceLabelEnricher backed by lister.
+func NewNamespaceLabelEnricher(lister corev1listers.NamespaceLister) *NamespaceLabelEnricher {
+	return &NamespaceLabelEnricher{NamespaceLister: lister}
+}
+
+// Enrich sets evalContext.NamespaceLabels to the labels of req.Namespace, as observed by the
+// informer cache backing e.NamespaceLister.
+func (e *NamespaceLabelEnricher) Enrich(ctx context.Context, req *admissionv1.AdmissionRequest, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, evalContext *policy.EvaluationContext) error {
+	ns, err := e.NamespaceLister.Get(req.Namespace)
+	if err != nil {
+		return err
+	}
+	evalContext.NamespaceLabels = ns.Labels
+	return nil
+}
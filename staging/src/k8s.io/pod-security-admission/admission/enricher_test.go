@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/pod-security-admission/policy"
+)
+
+type fakeEnricher struct {
+	delay time.Duration
+	err   error
+	apply func(*policy.EvaluationContext)
+}
+
+func (f *fakeEnricher) Enrich(ctx context.Context, req *admissionv1.AdmissionRequest, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, evalContext *policy.EvaluationContext) error {
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return f.err
+	}
+	if f.apply != nil {
+		f.apply(evalContext)
+	}
+	return nil
+}
+
+func TestCompositeEnricher(t *testing.T) {
+	req := &admissionv1.AdmissionRequest{Namespace: "test-ns"}
+
+	t.Run("runs every enricher in order", func(t *testing.T) {
+		c := &CompositeEnricher{Enrichers: []Enricher{
+			&fakeEnricher{apply: func(ec *policy.EvaluationContext) { ec.RuntimeClass = "gvisor" }},
+			&fakeEnricher{apply: func(ec *policy.EvaluationContext) { ec.NodeSelector = map[string]string{"zone": "us-east"} }},
+		}}
+		var evalContext policy.EvaluationContext
+		require.NoError(t, c.Enrich(context.Background(), req, nil, nil, &evalContext))
+		assert.Equal(t, "gvisor", evalContext.RuntimeClass)
+		assert.Equal(t, map[string]string{"zone": "us-east"}, evalContext.NodeSelector)
+	})
+
+	t.Run("a failing enricher does not fail admission or block later enrichers", func(t *testing.T) {
+		c := &CompositeEnricher{Enrichers: []Enricher{
+			&fakeEnricher{err: fmt.Errorf("namespace not found")},
+			&fakeEnricher{apply: func(ec *policy.EvaluationContext) { ec.RuntimeClass = "gvisor" }},
+		}}
+		var evalContext policy.EvaluationContext
+		assert.NoError(t, c.Enrich(context.Background(), req, nil, nil, &evalContext))
+		assert.Equal(t, "gvisor", evalContext.RuntimeClass)
+	})
+
+	t.Run("a slow enricher is cut off by PerEnricherTimeout", func(t *testing.T) {
+		c := &CompositeEnricher{
+			Enrichers:          []Enricher{&fakeEnricher{delay: time.Second}},
+			PerEnricherTimeout: time.Millisecond,
+		}
+		var evalContext policy.EvaluationContext
+		start := time.Now()
+		assert.NoError(t, c.Enrich(context.Background(), req, nil, nil, &evalContext))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// fakeNamespaceLister implements corev1listers.NamespaceLister against a fixed namespace, to
+// exercise NamespaceLabelEnricher without standing up a real informer.
+type fakeNamespaceLister struct {
+	ns *corev1.Namespace
+}
+
+func (f *fakeNamespaceLister) List(selector labels.Selector) ([]*corev1.Namespace, error) {
+	return []*corev1.Namespace{f.ns}, nil
+}
+
+func (f *fakeNamespaceLister) Get(name string) (*corev1.Namespace, error) {
+	if name != f.ns.Name {
+		return nil, fmt.Errorf("namespace %q not found", name)
+	}
+	return f.ns, nil
+}
+
+func TestNamespaceLabelEnricher(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"tier": "prod"}},
+	}
+	e := &NamespaceLabelEnricher{NamespaceLister: &fakeNamespaceLister{ns: ns}}
+	var evalContext policy.EvaluationContext
+	req := &admissionv1.AdmissionRequest{Namespace: "prod"}
+	require.NoError(t, e.Enrich(context.Background(), req, nil, nil, &evalContext))
+	assert.Equal(t, map[string]string{"tier": "prod"}, evalContext.NamespaceLabels)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PodSpecExtractorFunc extracts a pod template's metadata and PodSpec from obj, for use with
+// RegisterPodSpecExtractor.
+type PodSpecExtractorFunc func(obj runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, error)
+
+var (
+	podSpecExtractorsMu    sync.RWMutex
+	podSpecExtractorsByGR  = map[schema.GroupResource]PodSpecExtractorFunc{}
+	podSpecExtractorsByGVK = map[schema.GroupVersionKind]PodSpecExtractorFunc{}
+)
+
+// RegisterPodSpecExtractor lets an ecosystem controller's workload CRD (e.g. KusionStack
+// CollaSet, OpenKruise CloneSet/Advanced StatefulSet, Argo Rollout, OpenShift DeploymentConfig)
+// be evaluated by pod security admission without forking this module: gr is the GroupResource
+// admission sees on the request, gvk is the GroupVersionKind of the decoded object fn is given,
+// and fn extracts that object's embedded pod template metadata and PodSpec. Registering the same
+// gr or gvk again overwrites the previous registration. Intended to be called from an
+// operator's/controller's init, before the admission plugin starts evaluating requests.
+func RegisterPodSpecExtractor(gr schema.GroupResource, gvk schema.GroupVersionKind, fn PodSpecExtractorFunc) {
+	podSpecExtractorsMu.Lock()
+	defer podSpecExtractorsMu.Unlock()
+	podSpecExtractorsByGR[gr] = fn
+	podSpecExtractorsByGVK[gvk] = fn
+}
+
+// CompositePodSpecExtractor is a PodSpecExtractor that consults extractors registered via
+// RegisterPodSpecExtractor before falling back to Default for the built-in kinds. Use this
+// instead of DefaultPodSpecExtractor when the cluster may admit workload CRDs from ecosystem
+// controllers that embed a pod template under a non-standard field.
+type CompositePodSpecExtractor struct {
+	// Default is consulted when obj's GroupResource/GroupVersionKind has no registered extractor.
+	Default PodSpecExtractor
+}
+
+var _ PodSpecExtractor = &CompositePodSpecExtractor{}
+
+// NewCompositePodSpecExtractor returns a CompositePodSpecExtractor falling back to
+// DefaultPodSpecExtractor.
+func NewCompositePodSpecExtractor() *CompositePodSpecExtractor {
+	return &CompositePodSpecExtractor{Default: &DefaultPodSpecExtractor{}}
+}
+
+// HasPodSpec returns whether gr has a registered extractor, falling back to c.Default.
+func (c *CompositePodSpecExtractor) HasPodSpec(gr schema.GroupResource) bool {
+	podSpecExtractorsMu.RLock()
+	_, ok := podSpecExtractorsByGR[gr]
+	podSpecExtractorsMu.RUnlock()
+	if ok {
+		return true
+	}
+	return c.Default.HasPodSpec(gr)
+}
+
+// ExtractPodSpec dispatches to obj's registered extractor by GroupVersionKind, falling back to
+// c.Default when obj's kind has no registered extractor.
+func (c *CompositePodSpecExtractor) ExtractPodSpec(obj runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	podSpecExtractorsMu.RLock()
+	fn, ok := podSpecExtractorsByGVK[gvk]
+	podSpecExtractorsMu.RUnlock()
+	if ok {
+		return fn(obj)
+	}
+	return c.Default.ExtractPodSpec(obj)
+}
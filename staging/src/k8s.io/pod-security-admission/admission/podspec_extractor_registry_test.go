@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// rollout stands in for a third-party workload CRD (e.g. Argo's Rollout) that embeds a pod
+// template under a non-standard field, to exercise RegisterPodSpecExtractor /
+// CompositePodSpecExtractor.
+type rollout struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Template corev1.PodTemplateSpec
+}
+
+func (r *rollout) DeepCopyObject() runtime.Object {
+	out := *r
+	return &out
+}
+
+var rolloutGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+var rolloutGR = schema.GroupResource{Group: "argoproj.io", Resource: "rollouts"}
+
+func TestCompositePodSpecExtractor(t *testing.T) {
+	RegisterPodSpecExtractor(rolloutGR, rolloutGVK, func(obj runtime.Object) (*metav1.ObjectMeta, *corev1.PodSpec, error) {
+		r := obj.(*rollout)
+		return &r.Template.ObjectMeta, &r.Template.Spec, nil
+	})
+
+	extractor := NewCompositePodSpecExtractor()
+
+	assert.True(t, extractor.HasPodSpec(rolloutGR), "registered GroupResource should be recognized")
+	assert.True(t, extractor.HasPodSpec(corev1.Resource("pods")), "built-in kinds should still fall back to Default")
+	assert.False(t, extractor.HasPodSpec(corev1.Resource("services")))
+
+	r := &rollout{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-rollout"},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-pod"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "foo-container"}}},
+		},
+	}
+	meta, spec, err := extractor.ExtractPodSpec(r)
+	require.NoError(t, err)
+	assert.Equal(t, &r.Template.ObjectMeta, meta)
+	assert.Equal(t, &r.Template.Spec, spec)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-pod"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "foo-container"}}},
+	}
+	meta, spec, err = extractor.ExtractPodSpec(pod)
+	require.NoError(t, err)
+	assert.Equal(t, &pod.ObjectMeta, meta)
+	assert.Equal(t, &pod.Spec, spec)
+}
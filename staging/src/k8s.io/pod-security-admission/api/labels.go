@@ -0,0 +1,29 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+// Labels configuring Pod Security admission on a namespace. Each mode (enforce/audit/warn) has a
+// Level label and a companion Version label pinning the policy version that Level is evaluated
+// at; an unset Version label (or "latest") evaluates at the newest version this binary knows.
+const (
+	EnforceLevelLabel   = "pod-security.kubernetes.io/enforce"
+	EnforceVersionLabel = "pod-security.kubernetes.io/enforce-version"
+	AuditLevelLabel     = "pod-security.kubernetes.io/audit"
+	AuditVersionLabel   = "pod-security.kubernetes.io/audit-version"
+	WarnLevelLabel      = "pod-security.kubernetes.io/warn"
+	WarnVersionLabel    = "pod-security.kubernetes.io/warn-version"
+)
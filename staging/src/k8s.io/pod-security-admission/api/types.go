@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package api holds the types shared between the pod-security-admission policy and admission
+// packages: the three policy Levels, the policy Version they're evaluated at, and the pairing of
+// the two used to configure a namespace.
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level is a pod security policy level as defined by the Pod Security Standards.
+type Level string
+
+const (
+	LevelPrivileged Level = "privileged"
+	LevelBaseline   Level = "baseline"
+	LevelRestricted Level = "restricted"
+)
+
+func (l Level) String() string {
+	return string(l)
+}
+
+// Version is a policy version, e.g. v1.24, or the "latest" sentinel, which compares newer than
+// every concrete version.
+type Version struct {
+	major, minor int
+	latest       bool
+}
+
+// LatestVersion returns the sentinel version that is newer than every concrete version.
+func LatestVersion() Version {
+	return Version{latest: true}
+}
+
+// MajorMinorVersion constructs the concrete version major.minor.
+func MajorMinorVersion(major, minor int) Version {
+	return Version{major: major, minor: minor}
+}
+
+// VersionToEvaluate parses a version string such as "v1.24" or "latest" (including the empty
+// string, which is treated as "latest").
+func VersionToEvaluate(v string) (Version, error) {
+	if v == "" || v == "latest" {
+		return LatestVersion(), nil
+	}
+	trimmed := strings.TrimPrefix(v, "v")
+	parts := strings.SplitN(trimmed, ".", 2)
+	if len(parts) != 2 {
+		return Version{}, fmt.Errorf("invalid version %q: expected vMAJOR.MINOR or \"latest\"", v)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %v", v, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %v", v, err)
+	}
+	return MajorMinorVersion(major, minor), nil
+}
+
+// Major returns the major version component; 0 for the latest sentinel.
+func (v Version) Major() int {
+	return v.major
+}
+
+// Minor returns the minor version component; 0 for the latest sentinel.
+func (v Version) Minor() int {
+	return v.minor
+}
+
+// Older returns whether v is strictly older than other.
+func (v Version) Older(other Version) bool {
+	if v.latest {
+		return false
+	}
+	if other.latest {
+		return true
+	}
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	return v.minor < other.minor
+}
+
+func (v Version) String() string {
+	if v.latest {
+		return "latest"
+	}
+	return fmt.Sprintf("v%d.%d", v.major, v.minor)
+}
+
+// LevelVersion pairs a policy Level with the Version it should be evaluated at.
+type LevelVersion struct {
+	Level   Level
+	Version Version
+}
+
+func (lv LevelVersion) String() string {
+	return fmt.Sprintf("%s:%s", lv.Level, lv.Version)
+}
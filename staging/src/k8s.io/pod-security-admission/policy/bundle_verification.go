@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	bundleSHA256Annotation    = "policy.kubernetes.io/sha256"
+	bundleSignatureAnnotation = "policy.kubernetes.io/cosign-signature"
+)
+
+// BundleSource pairs the object and raw payload an external policy bundle was decoded from, for
+// RegistrationOptions.Verification.
+type BundleSource struct {
+	// Object carries the bundleSHA256Annotation and, if BundleKeyring.Keys is set,
+	// bundleSignatureAnnotation annotations to verify Raw against.
+	Object metav1.Object
+	// Raw is the undecoded bundle payload Object's annotations were computed over.
+	Raw []byte
+}
+
+// BundleKeyring configures verification of an external policy bundle's authenticity before its
+// checks are registered: the SHA-256 digest annotation is always checked, and, when Keys is
+// non-empty, a detached signature annotation is checked against them too. A signature that
+// validates against any key in Keys is accepted, so a key can be rotated in before the old one is
+// retired.
+type BundleKeyring struct {
+	Keys []*ecdsa.PublicKey
+}
+
+// BundleVerification is RegistrationOptions.Verification: when set, RegisterExternalChecks
+// rejects the bundle unless it passes verification against Keyring.
+type BundleVerification struct {
+	Source  BundleSource
+	Keyring BundleKeyring
+}
+
+// bundleCacheKey identifies one verification result: a specific object at a specific
+// resourceVersion, checked against a specific set of keys. resourceVersion alone isn't globally
+// unique, so it's paired with the object's UID; the keys are part of the key so that retiring a
+// key takes effect.
+type bundleCacheKey struct {
+	uid             types.UID
+	resourceVersion string
+	keyring         string
+}
+
+var (
+	bundleCacheMu sync.Mutex
+	bundleCache   = map[bundleCacheKey]error{}
+)
+
+// verifyBundle checks v.Source.Raw against the bundleSHA256Annotation and, if v.Keyring.Keys is
+// non-empty, bundleSignatureAnnotation annotations on v.Source.Object. Results are cached by the
+// object's (UID, resourceVersion) together with the keyring's set of keys, so registering the same
+// bundle version repeatedly (e.g. on every informer resync) doesn't re-hash or re-verify it.
+// Objects with no resourceVersion (not yet persisted) are never cached.
+func verifyBundle(v BundleVerification) error {
+	if v.Source.Object == nil {
+		return fmt.Errorf("policy bundle verification: no source object provided")
+	}
+	keyringID := ""
+	for _, k := range v.Keyring.Keys {
+		keyringID += fmt.Sprintf("|%p", k)
+	}
+	key := bundleCacheKey{uid: v.Source.Object.GetUID(), resourceVersion: v.Source.Object.GetResourceVersion(), keyring: keyringID}
+	if key.resourceVersion != "" {
+		bundleCacheMu.Lock()
+		err, ok := bundleCache[key]
+		bundleCacheMu.Unlock()
+		if ok {
+			return err
+		}
+	}
+
+	err := verifyBundleUncached(v)
+
+	if key.resourceVersion != "" {
+		bundleCacheMu.Lock()
+		bundleCache[key] = err
+		bundleCacheMu.Unlock()
+	}
+	return err
+}
+
+func verifyBundleUncached(v BundleVerification) error {
+	annotations := v.Source.Object.GetAnnotations()
+	wantDigest := annotations[bundleSHA256Annotation]
+	if wantDigest == "" {
+		return fmt.Errorf("policy bundle verification: missing %s annotation", bundleSHA256Annotation)
+	}
+	sum := sha256.Sum256(v.Source.Raw)
+	gotDigest := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(gotDigest), []byte(wantDigest)) != 1 {
+		return fmt.Errorf("policy bundle verification: payload does not match %s annotation", bundleSHA256Annotation)
+	}
+
+	if len(v.Keyring.Keys) == 0 {
+		return nil
+	}
+
+	encodedSig := annotations[bundleSignatureAnnotation]
+	if encodedSig == "" {
+		return fmt.Errorf("policy bundle verification: keyring configured but %s annotation is missing", bundleSignatureAnnotation)
+	}
+	sig, err := base64.StdEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return fmt.Errorf("policy bundle verification: invalid %s annotation: %v", bundleSignatureAnnotation, err)
+	}
+	for _, k := range v.Keyring.Keys {
+		if k != nil && ecdsa.VerifyASN1(k, sum[:], sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("policy bundle verification: signature in %s does not verify against any key in the configured keyring", bundleSignatureAnnotation)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func signedBundleSource(t *testing.T, raw []byte, resourceVersion string, key *ecdsa.PrivateKey) BundleSource {
+	t.Helper()
+	sum := sha256.Sum256(raw)
+	annotations := map[string]string{bundleSHA256Annotation: hex.EncodeToString(sum[:])}
+	if key != nil {
+		sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+		require.NoError(t, err)
+		annotations[bundleSignatureAnnotation] = base64.StdEncoding.EncodeToString(sig)
+	}
+	return BundleSource{
+		Object: &metav1.ObjectMeta{Name: "my-bundle", ResourceVersion: resourceVersion, Annotations: annotations},
+		Raw:    raw,
+	}
+}
+
+func TestVerifyBundle_DigestOnly(t *testing.T) {
+	raw := []byte("policy bundle v1")
+
+	t.Run("matching digest is allowed", func(t *testing.T) {
+		source := signedBundleSource(t, raw, "1", nil)
+		assert.NoError(t, verifyBundle(BundleVerification{Source: source}))
+	})
+
+	t.Run("tampered payload is rejected", func(t *testing.T) {
+		source := signedBundleSource(t, raw, "2", nil)
+		source.Raw = []byte("policy bundle v2")
+		assert.Error(t, verifyBundle(BundleVerification{Source: source}))
+	})
+
+	t.Run("missing digest annotation is rejected", func(t *testing.T) {
+		source := BundleSource{Object: &metav1.ObjectMeta{Name: "my-bundle", ResourceVersion: "3"}, Raw: raw}
+		assert.Error(t, verifyBundle(BundleVerification{Source: source}))
+	})
+}
+
+func TestVerifyBundle_Signature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw := []byte("policy bundle v1")
+
+	t.Run("valid signature is allowed", func(t *testing.T) {
+		source := signedBundleSource(t, raw, "1", key)
+		keyring := BundleKeyring{Keys: []*ecdsa.PublicKey{&key.PublicKey}}
+		assert.NoError(t, verifyBundle(BundleVerification{Source: source, Keyring: keyring}))
+	})
+
+	t.Run("signature from untrusted key is rejected", func(t *testing.T) {
+		source := signedBundleSource(t, raw, "2", otherKey)
+		keyring := BundleKeyring{Keys: []*ecdsa.PublicKey{&key.PublicKey}}
+		assert.Error(t, verifyBundle(BundleVerification{Source: source, Keyring: keyring}))
+	})
+
+	t.Run("key rotation: old and new key both verify until the old one is retired", func(t *testing.T) {
+		rotating := BundleKeyring{Keys: []*ecdsa.PublicKey{&key.PublicKey, &otherKey.PublicKey}}
+		bySelf := signedBundleSource(t, raw, "3", key)
+		byNew := signedBundleSource(t, raw, "4", otherKey)
+		assert.NoError(t, verifyBundle(BundleVerification{Source: bySelf, Keyring: rotating}))
+		assert.NoError(t, verifyBundle(BundleVerification{Source: byNew, Keyring: rotating}))
+
+		retired := BundleKeyring{Keys: []*ecdsa.PublicKey{&otherKey.PublicKey}}
+		assert.Error(t, verifyBundle(BundleVerification{Source: bySelf, Keyring: retired}))
+	})
+}
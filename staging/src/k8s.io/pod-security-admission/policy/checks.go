@@ -29,14 +29,26 @@ type Check interface {
 	CheckPod(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult
 }
 
+// UpdateCheck is an optional capability a Check can implement to evaluate a pod update without
+// re-running its full CheckPod logic. Pod Security Standards only police a handful of fields that
+// can change after creation (image, activeDeadlineSeconds, tolerations, and a few container
+// fields), so most checks can allow a mutation outright once they've confirmed it doesn't touch
+// the attributes they govern, instead of re-evaluating the whole (possibly large) new pod spec.
+type UpdateCheck interface {
+	Check
+	// CheckPodUpdate determines if the pod mutation from old to new is allowed.
+	CheckPodUpdate(oldPodMetadata *metav1.ObjectMeta, oldPodSpec *corev1.PodSpec, newPodMetadata *metav1.ObjectMeta, newPodSpec *corev1.PodSpec) CheckResult
+}
+
 // CheckResult contains the result of checking a pod and indicates whether the pod is allowed,
 // and if not, why it was forbidden.
 //
 // Example output for (false, "host ports", "8080, 9090"):
-//   When checking all pods in a namespace:
-//     disallowed by policy "baseline": host ports, privileged containers, non-default capabilities
-//   When checking an individual pod:
-//     disallowed by policy "baseline": host ports (8080, 9090), privileged containers, non-default capabilities (CAP_NET_RAW)
+//
+//	When checking all pods in a namespace:
+//	  disallowed by policy "baseline": host ports, privileged containers, non-default capabilities
+//	When checking an individual pod:
+//	  disallowed by policy "baseline": host ports (8080, 9090), privileged containers, non-default capabilities (CAP_NET_RAW)
 type CheckResult struct {
 	// Allowed indicates if the check allowed the pod.
 	Allowed bool
@@ -46,8 +58,35 @@ type CheckResult struct {
 	// ForbiddenDetail should only be set if Allowed is false.
 	// ForbiddenDetail can include specific values that were disallowed and is used when checking an individual object.
 	ForbiddenDetail string
+	// Kind distinguishes how the result was reached, e.g. for audit logging. Unset for results
+	// produced by an individual Check; set to CheckResultWarning on the deprecation-window previews
+	// Registry.CheckPod and CheckPodUpdate return, and set by Registry.CheckPodWithExemptions on
+	// every result it returns.
+	Kind CheckResultKind
+	// Source identifies who contributed the check that produced this result, e.g. "example.com".
+	// Empty for the checks built into the Registry; set to the RegistrationOptions.Source of the
+	// checks added via Registry.RegisterExternalChecks, so admission logs can distinguish upstream
+	// findings from organization-specific ones.
+	Source string
 }
 
+// CheckResultKind distinguishes the different ways a CheckPodWithExemptions result was reached.
+type CheckResultKind string
+
+const (
+	// CheckResultCompliant indicates the pod was evaluated against the policy and allowed.
+	CheckResultCompliant CheckResultKind = "Compliant"
+	// CheckResultForbidden indicates the pod was evaluated against the policy and disallowed.
+	CheckResultForbidden CheckResultKind = "Forbidden"
+	// CheckResultExempt indicates the pod was not evaluated against the policy at all, because it
+	// matched an exemption rule.
+	CheckResultExempt CheckResultKind = "Exempt"
+	// CheckResultWarning indicates the pod passed the checks in effect for the evaluated version,
+	// but would be forbidden once a check currently in its deprecation window takes effect. Unlike
+	// CheckResultForbidden, a warning never fails admission; Allowed is always true.
+	CheckResultWarning CheckResultKind = "Warning"
+)
+
 // CheckDocumentation is used to generate documentation for checks.
 type CheckDocumentation interface {
 	// Name returns a short human-readable string, used for the left column of the docs
@@ -139,3 +178,32 @@ func AggregateCheckPod(checks []Check, podMetadata *metav1.ObjectMeta, podSpec *
 		ForbiddenDetails: details,
 	}
 }
+
+// AggregateCheckPodUpdate is like AggregateCheckPod, but for a pod mutation: checks implementing
+// UpdateCheck are given both the old and new pod via CheckPodUpdate, so they can allow a mutation
+// that doesn't touch the attributes they govern (e.g. an image bump) without re-checking the rest
+// of the new pod spec. Checks that don't implement UpdateCheck fall back to
+// CheckPod(newPodMetadata, newPodSpec), same as they would for a CREATE.
+func AggregateCheckPodUpdate(checks []Check, oldPodMetadata, newPodMetadata *metav1.ObjectMeta, oldPodSpec, newPodSpec *corev1.PodSpec) AggregateCheckResult {
+	var (
+		reasons []string
+		details []string
+	)
+	for _, c := range checks {
+		var r CheckResult
+		if uc, ok := c.(UpdateCheck); ok {
+			r = uc.CheckPodUpdate(oldPodMetadata, oldPodSpec, newPodMetadata, newPodSpec)
+		} else {
+			r = c.CheckPod(newPodMetadata, newPodSpec)
+		}
+		if !r.Allowed {
+			reasons = append(reasons, r.ForbiddenReason)
+			details = append(details, r.ForbiddenDetail)
+		}
+	}
+	return AggregateCheckResult{
+		Allowed:          len(reasons) == 0,
+		ForbiddenReasons: reasons,
+		ForbiddenDetails: details,
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// privilegedCheck stands in for the real "privileged containers" baseline check: it forbids any
+// container with SecurityContext.Privileged set, and implements UpdateCheck so a mutation that
+// doesn't touch Privileged on any container is allowed without re-scanning the rest of the pod.
+type privilegedCheck struct{ check }
+
+func newPrivilegedCheck() *privilegedCheck {
+	c := &privilegedCheck{check: check{id: "privileged"}}
+	c.checkPod = c.checkPrivileged
+	return c
+}
+
+func (c *privilegedCheck) checkPrivileged(_ *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult {
+	for _, container := range podSpec.Containers {
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			return CheckResult{Allowed: false, ForbiddenReason: "privileged", ForbiddenDetail: container.Name}
+		}
+	}
+	return CheckResult{Allowed: true}
+}
+
+func (c *privilegedCheck) CheckPodUpdate(_ *metav1.ObjectMeta, oldPodSpec *corev1.PodSpec, newPodMetadata *metav1.ObjectMeta, newPodSpec *corev1.PodSpec) CheckResult {
+	if anyPrivileged(oldPodSpec) == anyPrivileged(newPodSpec) {
+		return CheckResult{Allowed: true}
+	}
+	return c.checkPrivileged(newPodMetadata, newPodSpec)
+}
+
+func anyPrivileged(podSpec *corev1.PodSpec) bool {
+	for _, container := range podSpec.Containers {
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			return true
+		}
+	}
+	return false
+}
+
+// hostNamespacesCheck stands in for the real "host namespaces" baseline check: it forbids
+// HostPID/HostIPC/HostNetwork, and implements UpdateCheck so it can allow mutations that leave
+// those fields untouched.
+type hostNamespacesCheck struct{ check }
+
+func newHostNamespacesCheck() *hostNamespacesCheck {
+	c := &hostNamespacesCheck{check: check{id: "hostNamespaces"}}
+	c.checkPod = c.checkHostNamespaces
+	return c
+}
+
+func (c *hostNamespacesCheck) checkHostNamespaces(_ *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult {
+	if podSpec.HostPID || podSpec.HostIPC || podSpec.HostNetwork {
+		return CheckResult{Allowed: false, ForbiddenReason: "host namespaces"}
+	}
+	return CheckResult{Allowed: true}
+}
+
+func (c *hostNamespacesCheck) CheckPodUpdate(_ *metav1.ObjectMeta, oldPodSpec *corev1.PodSpec, newPodMetadata *metav1.ObjectMeta, newPodSpec *corev1.PodSpec) CheckResult {
+	if hostNamespaces(oldPodSpec) == hostNamespaces(newPodSpec) {
+		return CheckResult{Allowed: true}
+	}
+	return c.checkHostNamespaces(newPodMetadata, newPodSpec)
+}
+
+func hostNamespaces(podSpec *corev1.PodSpec) [3]bool {
+	return [3]bool{podSpec.HostPID, podSpec.HostIPC, podSpec.HostNetwork}
+}
+
+// newAlwaysAllowedCheck stands in for a check that doesn't implement UpdateCheck (e.g. one that's
+// cheap enough it's never worth special-casing), to exercise AggregateCheckPodUpdate's fallback.
+func newAlwaysAllowedCheck(id string) *check {
+	c := &check{id: id}
+	c.checkPod = func(*metav1.ObjectMeta, *corev1.PodSpec) CheckResult {
+		return CheckResult{Allowed: true}
+	}
+	return c
+}
+
+func TestAggregateCheckPodUpdate(t *testing.T) {
+	checks := []Check{
+		newPrivilegedCheck(),
+		newHostNamespacesCheck(),
+		newAlwaysAllowedCheck("seccomp"),
+	}
+
+	basePod := func() *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "example.com/app:v1",
+			}},
+		}
+	}
+
+	testCases := []struct {
+		desc    string
+		mutate  func(*corev1.PodSpec)
+		allowed bool
+	}{
+		{
+			desc:    "image bump is allowed",
+			mutate:  func(spec *corev1.PodSpec) { spec.Containers[0].Image = "example.com/app:v2" },
+			allowed: true,
+		},
+		{
+			desc: "toleration add is allowed",
+			mutate: func(spec *corev1.PodSpec) {
+				spec.Tolerations = append(spec.Tolerations, corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists})
+			},
+			allowed: true,
+		},
+		{
+			desc: "flipping privileged is forbidden",
+			mutate: func(spec *corev1.PodSpec) {
+				t := true
+				spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &t}
+			},
+			allowed: false,
+		},
+		{
+			desc:    "adding hostPID is forbidden",
+			mutate:  func(spec *corev1.PodSpec) { spec.HostPID = true },
+			allowed: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			oldPodSpec := basePod()
+			newPodSpec := basePod()
+			test.mutate(newPodSpec)
+
+			result := AggregateCheckPodUpdate(checks, &metav1.ObjectMeta{}, &metav1.ObjectMeta{}, oldPodSpec, newPodSpec)
+			assert.Equal(t, test.allowed, result.Allowed, result.ForbiddenReason())
+		})
+	}
+}
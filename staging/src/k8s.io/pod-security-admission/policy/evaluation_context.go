@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EvaluationContext carries cluster metadata synthesized by admission-side enrichers before a
+// Check runs, so checks can consult data (namespace labels, the owning workload's runtimeClass,
+// image-signing attestations, node-selector projections) that would otherwise require every check
+// to re-fetch it itself.
+//
+// Fields are populated on a best-effort basis: an enricher that failed or timed out simply leaves
+// its field at its zero value, so checks must treat an empty value as "unknown", not "absent".
+type EvaluationContext struct {
+	// NamespaceLabels holds the labels of the namespace the pod belongs to.
+	NamespaceLabels map[string]string
+	// RuntimeClass holds the pod's effective RuntimeClass name, resolved from the pod spec or,
+	// for a pod-template-only object, its owning workload.
+	RuntimeClass string
+	// ImageAttestations maps each container image reference to whether it carried a verified
+	// signing attestation.
+	ImageAttestations map[string]bool
+	// NodeSelector holds the effective node selector the pod will be scheduled with, projected
+	// from the pod spec and any namespace-default node selector.
+	NodeSelector map[string]string
+}
+
+// ContextCheck is an optional capability a Check can implement to additionally consult an
+// EvaluationContext assembled by admission-side Enrichers. A check that only examines the pod
+// itself has no reason to implement this; one that wants to key a rule off cluster metadata (e.g.
+// "namespaces labeled tier=prod may not run privileged pods regardless of enforce level") does.
+type ContextCheck interface {
+	Check
+	// CheckPodInContext is like CheckPod, but additionally given the EvaluationContext assembled
+	// for this request.
+	CheckPodInContext(evalContext EvaluationContext, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult
+}
+
+// AggregateCheckPodInContext is like AggregateCheckPod, but passes evalContext to checks
+// implementing ContextCheck. Checks that don't implement ContextCheck fall back to
+// CheckPod(podMetadata, podSpec), same as they would outside an enriched request.
+func AggregateCheckPodInContext(checks []Check, evalContext EvaluationContext, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) AggregateCheckResult {
+	var (
+		reasons []string
+		details []string
+	)
+	for _, c := range checks {
+		var r CheckResult
+		if cc, ok := c.(ContextCheck); ok {
+			r = cc.CheckPodInContext(evalContext, podMetadata, podSpec)
+		} else {
+			r = c.CheckPod(podMetadata, podSpec)
+		}
+		if !r.Allowed {
+			reasons = append(reasons, r.ForbiddenReason)
+			details = append(details, r.ForbiddenDetail)
+		}
+	}
+	return AggregateCheckResult{
+		Allowed:          len(reasons) == 0,
+		ForbiddenReasons: reasons,
+		ForbiddenDetails: details,
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/pod-security-admission/api"
+)
+
+// checkFunc is the signature of a single version of a check's pod evaluation logic. Unlike Check,
+// it is a plain function, not an interface, since each VersionedCheck contributes exactly one
+// implementation and has no need for an ID() of its own (that's carried by the enclosing
+// LevelCheck).
+type checkFunc func(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult
+
+// checkUpdateFunc is the signature of a single version of a check's pod-update evaluation logic.
+// See VersionedCheck.CheckPodUpdate.
+type checkUpdateFunc func(oldPodMetadata *metav1.ObjectMeta, oldPodSpec *corev1.PodSpec, newPodMetadata *metav1.ObjectMeta, newPodSpec *corev1.PodSpec) CheckResult
+
+// LevelCheck documents and implements a single policy check, across all the versions it has
+// existed in.
+type LevelCheck struct {
+	// ID is the unique identifying name for this check.
+	ID string
+	// Level is the policy level this check is part of.
+	Level api.Level
+	// Versions is the set of versions this check has been available, oldest first. Versions must
+	// be strictly increasing, and must not be empty.
+	Versions []VersionedCheck
+}
+
+// VersionedCheck is a single version of a LevelCheck's behavior: the logic in effect from
+// MinimumVersion onwards, until a newer VersionedCheck of the same LevelCheck supersedes it.
+type VersionedCheck struct {
+	// MinimumVersion is the first policy version this check applies to, e.g. "v1.24". Required.
+	MinimumVersion string
+	// DeprecatedInVersion is the policy version at which the *next* VersionedCheck in the
+	// LevelCheck's Versions slice, if any, should start being surfaced early as a
+	// CheckResultWarning preview, ahead of its own MinimumVersion actually taking effect. This
+	// lets cluster admins see the effect of a future version bump without it failing admission.
+	// Optional; only meaningful when a later VersionedCheck exists.
+	DeprecatedInVersion string
+	// RemovedInVersion is the policy version at which this check variant stops being enforced
+	// entirely, even if no later VersionedCheck supersedes it. Optional; defaults to remaining in
+	// effect until superseded or, for the last entry, indefinitely.
+	RemovedInVersion string
+	// CheckPod determines if the pod is allowed under this version of the check.
+	CheckPod checkFunc
+	// CheckPodUpdate, if set, determines if a pod mutation is allowed under this version of the
+	// check without re-running the full CheckPod logic against the new pod. Optional: checks that
+	// don't set it are re-evaluated via CheckPod(newPodMetadata, newPodSpec) on every update, same
+	// as on CREATE.
+	CheckPodUpdate checkUpdateFunc
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"time"
+
+	"k8s.io/pod-security-admission/api"
+)
+
+// MetricsRecorder receives per-check timing as CheckPod evaluates a pod, letting operators wire
+// PSA check latency into Prometheus (or any other backend) without CheckPod itself depending on
+// one. Implementations must be safe for concurrent use: CheckPod may call RecordCheck for several
+// checks of the same pod at once.
+type MetricsRecorder interface {
+	// RecordCheck is called once for every check CheckPod evaluates for a pod, including checks
+	// that are only running as a deprecation-window preview (see CheckResultWarning).
+	RecordCheck(id string, level api.Level, version api.Version, duration time.Duration, allowed bool)
+}
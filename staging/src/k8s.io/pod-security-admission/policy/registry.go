@@ -18,65 +18,414 @@ package policy
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/pod-security-admission/api"
 )
 
+// maxCheckConcurrency bounds how many checks CheckPod evaluates in parallel for a single pod.
+const maxCheckConcurrency = 8
+
 // Registry holds the Checks that are used to validate a policy.
 type Registry interface {
-	// CheckPod checks the given pod against all the checks registered for the given level & version.
-	CheckPod(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) []CheckResult
+	// CheckPod checks the given pod against all the checks registered for the given level &
+	// version, and returns the LevelVersion actually evaluated. effective.Version differs from
+	// lv.Version when lv.Version is newer than any version the registry knows about: by default
+	// it is clamped down to the newest known version, but if the registry was constructed with
+	// RegistryOptions.RejectUnknownVersions, err is returned instead.
+	CheckPod(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) (results []CheckResult, effective api.LevelVersion, err error)
+	// CheckPodUpdate is like CheckPod, but for a pod mutation: checks registered with a
+	// VersionedCheck.CheckPodUpdate are evaluated against the old and new pod instead of the new
+	// pod alone, so they can allow a mutation that doesn't touch the attributes they govern (e.g.
+	// an image bump) without re-running their full CheckPod logic. Checks with no CheckPodUpdate
+	// registered fall back to CheckPod(newPodMetadata, newPodSpec).
+	CheckPodUpdate(lv api.LevelVersion, oldPodMetadata, newPodMetadata *metav1.ObjectMeta, oldPodSpec, newPodSpec *corev1.PodSpec) (results []CheckResult, effective api.LevelVersion, err error)
+	// CheckPodWithExemptions is like CheckPod, but first checks whether the pod, its namespace, or
+	// its ServiceAccount or RuntimeClass match exemptions. If so, it short-circuits to a single
+	// CheckResult of kind CheckResultExempt, rather than running the registered checks at all.
+	CheckPodWithExemptions(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, nsMetadata *metav1.ObjectMeta, exemptions Exemptions) (results []CheckResult, effective api.LevelVersion, err error)
+	// RegisterExternalChecks layers organization-specific checks on top of the checks the Registry
+	// was constructed with, without forking the package. Each check's ID must be namespaced (e.g.
+	// "example.com/no-hostpath") and must not collide with a previously registered ID. Results
+	// produced by these checks carry opts.Source, so admission logs can distinguish them from the
+	// built-in checks.
+	RegisterExternalChecks(checks []LevelCheck, opts RegistrationOptions) error
+	// ChecksForLevelAndVersion returns the IDs of the checks that CheckPod would run for lv:
+	// baseline's checks (since CheckPod always evaluates baseline's checks too), then, for
+	// restricted, restricted's own, each in registration order, deduplicated. A version newer than
+	// the registry knows is clamped to the newest known version, whatever
+	// RegistryOptions.RejectUnknownVersions says. Callers that need to
+	// stay in lockstep with the registry as it gains checks across versions (e.g. to synthesize an
+	// equivalent PodSecurityPolicy) can use this instead of re-deriving the check set themselves.
+	ChecksForLevelAndVersion(lv api.LevelVersion) []string
+}
+
+// RegistrationOptions configures the checks passed to Registry.RegisterExternalChecks.
+type RegistrationOptions struct {
+	// Source identifies who is registering these checks, e.g. "example.com". Required; recorded
+	// on every CheckResult produced by the registered checks.
+	Source string
+	// Verification, if set, requires that the bundle's source object and raw payload pass
+	// verification before its checks are registered. Set this when checks are loaded from a
+	// ConfigMap or CRD that isn't otherwise authenticated, e.g. a cluster-admin-controlled policy
+	// bundle.
+	Verification *BundleVerification
+}
+
+// RegistryOptions configures how a Registry evaluates the checks it was constructed with.
+type RegistryOptions struct {
+	// RejectUnknownVersions, if true, makes CheckPod and CheckPodWithExemptions return an error
+	// instead of silently clamping lv.Version down to the newest version the registry knows about
+	// when lv.Version is newer than that. Set this so control planes fail closed on version skew
+	// (e.g. a namespace pinned to a PSA version newer than this binary evaluates) rather than
+	// silently under-enforcing.
+	RejectUnknownVersions bool
+	// MetricsRecorder, if set, is notified of each check's duration and outcome as CheckPod
+	// evaluates it.
+	MetricsRecorder MetricsRecorder
+}
+
+// registeredCheck pairs a checkFunc with the ID it was registered under, so CheckPod can attribute
+// per-check timing back to a MetricsRecorder without every caller threading IDs through checkFunc.
+type registeredCheck struct {
+	id string
+	fn checkFunc
+	// updateFn is the check's CheckPodUpdate variant, if it registered one; nil otherwise, in
+	// which case update evaluation falls back to fn(newPodMetadata, newPodSpec).
+	updateFn checkUpdateFunc
+}
+
+// externalIDPattern matches the namespaced ID external checks must use: a DNS-style domain
+// prefix, a slash, and a name, e.g. "example.com/no-hostpath".
+var externalIDPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)+/[a-zA-Z0-9](?:[a-zA-Z0-9._-]*[a-zA-Z0-9])?$`)
+
+// Exemptions specifies a policy exemption which allows bypassing the enforcement of pod security
+// requirements. A pod is exempt if any one of the fields matches the pod, its namespace, its
+// ServiceAccount, or its RuntimeClass.
+type Exemptions struct {
+	// Namespaces is a list of exempt namespaces. Exact match, no subpath or regex.
+	Namespaces []string
+	// ServiceAccounts is a list of exempt ServiceAccounts, of the form
+	// `<serviceaccount namespace>:<serviceaccount name>`. Exact match, no subpath or regex.
+	ServiceAccounts []string
+	// RuntimeClasses is a list of exempt RuntimeClassNames. Exact match, no subpath or regex.
+	RuntimeClasses []string
+}
+
+// exempt returns whether the pod is exempt from policy enforcement under e, given the namespace
+// metadata for the pod's namespace (nil if unknown).
+func (e *Exemptions) exempt(nsMetadata *metav1.ObjectMeta, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) bool {
+	if nsMetadata != nil {
+		for _, ns := range e.Namespaces {
+			if ns == nsMetadata.Name {
+				return true
+			}
+		}
+	}
+	if podSpec.ServiceAccountName != "" && podMetadata != nil {
+		serviceAccount := podMetadata.Namespace + ":" + podSpec.ServiceAccountName
+		for _, sa := range e.ServiceAccounts {
+			if sa == serviceAccount {
+				return true
+			}
+		}
+	}
+	if podSpec.RuntimeClassName != nil {
+		for _, rc := range e.RuntimeClasses {
+			if rc == *podSpec.RuntimeClassName {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // checkRegistry provides a default implementation of a Registry.
 type checkRegistry struct {
-	// The checks are a map of check_ID -> sorted slice of versioned checks, newest first
-	baselineChecks, restrictedChecks map[api.Version][]Check
+	// The checks are a map of version -> the checks in effect at that version, newest first.
+	baselineChecks, restrictedChecks map[api.Version][]registeredCheck
+	// The warning checks are previews of a check that is still in its deprecation window: checks
+	// in effect for a future version, surfaced early as CheckResultWarning results rather than
+	// being enforced.
+	baselineWarningChecks, restrictedWarningChecks map[api.Version][]registeredCheck
 	// maxVersion is the maximum version that is cached, guaranteed to be at least
 	// the max MinimumVersion of all registered checks.
 	maxVersion api.Version
+	// ids is the set of check IDs registered so far, built-in and external, used to reject
+	// collisions in RegisterExternalChecks.
+	ids map[string]bool
+	// options controls how CheckPod evaluates requests for versions the registry doesn't know
+	// about.
+	options RegistryOptions
 }
 
-func NewCheckRegistry(checks []LevelCheck) (Registry, error) {
-	if err := validateChecks(checks); err != nil {
-		return nil, err
-	}
+func NewCheckRegistry(checks []LevelCheck, opts RegistryOptions) (Registry, error) {
 	r := &checkRegistry{
-		baselineChecks:   map[api.Version][]Check{},
-		restrictedChecks: map[api.Version][]Check{},
+		baselineChecks:          map[api.Version][]registeredCheck{},
+		restrictedChecks:        map[api.Version][]registeredCheck{},
+		baselineWarningChecks:   map[api.Version][]registeredCheck{},
+		restrictedWarningChecks: map[api.Version][]registeredCheck{},
+		ids:                     map[string]bool{},
+		options:                 opts,
+	}
+	if err := validateChecks(checks, r.ids, false); err != nil {
+		return nil, err
 	}
 	populate(r, checks)
 	return r, nil
 }
 
-func (r *checkRegistry) CheckPod(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) []CheckResult {
+// RegisterExternalChecks validates and layers checks on top of the checks already registered in r.
+// See the Registry interface for details.
+func (r *checkRegistry) RegisterExternalChecks(checks []LevelCheck, opts RegistrationOptions) error {
+	if opts.Source == "" {
+		return fmt.Errorf("registering external checks: source is required")
+	}
+	if opts.Verification != nil {
+		if err := verifyBundle(*opts.Verification); err != nil {
+			return fmt.Errorf("registering external checks from %s: %w", opts.Source, err)
+		}
+	}
+	if err := validateChecks(checks, r.ids, true); err != nil {
+		return err
+	}
+	tagged := tagCheckSource(checks, opts.Source)
+	populate(r, tagged)
+	return nil
+}
+
+// tagCheckSource returns a copy of checks whose CheckPod functions annotate their CheckResult with
+// source, leaving the original checks (and their callers) unaffected.
+func tagCheckSource(checks []LevelCheck, source string) []LevelCheck {
+	tagged := make([]LevelCheck, len(checks))
+	for i, c := range checks {
+		tagged[i] = c
+		tagged[i].Versions = make([]VersionedCheck, len(c.Versions))
+		for j, v := range c.Versions {
+			checkPod := v.CheckPod
+			v.CheckPod = func(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) CheckResult {
+				result := checkPod(podMetadata, podSpec)
+				result.Source = source
+				return result
+			}
+			if checkPodUpdate := v.CheckPodUpdate; checkPodUpdate != nil {
+				v.CheckPodUpdate = func(oldPodMetadata *metav1.ObjectMeta, oldPodSpec *corev1.PodSpec, newPodMetadata *metav1.ObjectMeta, newPodSpec *corev1.PodSpec) CheckResult {
+					result := checkPodUpdate(oldPodMetadata, oldPodSpec, newPodMetadata, newPodSpec)
+					result.Source = source
+					return result
+				}
+			}
+			tagged[i].Versions[j] = v
+		}
+	}
+	return tagged
+}
+
+func (r *checkRegistry) CheckPod(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) ([]CheckResult, api.LevelVersion, error) {
 	if lv.Level == api.LevelPrivileged {
-		return nil
+		return nil, lv, nil
 	}
 	if r.maxVersion.Older(lv.Version) {
+		if r.options.RejectUnknownVersions {
+			return nil, lv, fmt.Errorf("version %s is newer than the newest version this registry evaluates (%s)", lv.Version, r.maxVersion)
+		}
 		lv.Version = r.maxVersion
 	}
-	results := []CheckResult{}
-	for _, check := range r.baselineChecks[lv.Version] {
-		results = append(results, check(podMetadata, podSpec))
+	results := r.runChecks(api.LevelBaseline, lv.Version, r.baselineChecks[lv.Version], podMetadata, podSpec)
+	results = append(results, r.warningResults(api.LevelBaseline, lv.Version, r.baselineWarningChecks[lv.Version], podMetadata, podSpec)...)
+	if lv.Level == api.LevelBaseline {
+		return results, lv, nil
+	}
+	results = append(results, r.runChecks(api.LevelRestricted, lv.Version, r.restrictedChecks[lv.Version], podMetadata, podSpec)...)
+	results = append(results, r.warningResults(api.LevelRestricted, lv.Version, r.restrictedWarningChecks[lv.Version], podMetadata, podSpec)...)
+	return results, lv, nil
+}
+
+// CheckPodUpdate checks the given pod mutation against all the checks registered for the given
+// level & version, dispatching to each check's CheckPodUpdate when it registered one. See the
+// Registry interface for details.
+func (r *checkRegistry) CheckPodUpdate(lv api.LevelVersion, oldPodMetadata, newPodMetadata *metav1.ObjectMeta, oldPodSpec, newPodSpec *corev1.PodSpec) ([]CheckResult, api.LevelVersion, error) {
+	if lv.Level == api.LevelPrivileged {
+		return nil, lv, nil
+	}
+	if r.maxVersion.Older(lv.Version) {
+		if r.options.RejectUnknownVersions {
+			return nil, lv, fmt.Errorf("version %s is newer than the newest version this registry evaluates (%s)", lv.Version, r.maxVersion)
+		}
+		lv.Version = r.maxVersion
 	}
+	results := r.runChecksForUpdate(api.LevelBaseline, lv.Version, r.baselineChecks[lv.Version], oldPodMetadata, newPodMetadata, oldPodSpec, newPodSpec)
+	results = append(results, r.warningResultsForUpdate(api.LevelBaseline, lv.Version, r.baselineWarningChecks[lv.Version], oldPodMetadata, newPodMetadata, oldPodSpec, newPodSpec)...)
 	if lv.Level == api.LevelBaseline {
-		return results
+		return results, lv, nil
 	}
-	for _, check := range r.restrictedChecks[lv.Version] {
-		results = append(results, check(podMetadata, podSpec))
+	results = append(results, r.runChecksForUpdate(api.LevelRestricted, lv.Version, r.restrictedChecks[lv.Version], oldPodMetadata, newPodMetadata, oldPodSpec, newPodSpec)...)
+	results = append(results, r.warningResultsForUpdate(api.LevelRestricted, lv.Version, r.restrictedWarningChecks[lv.Version], oldPodMetadata, newPodMetadata, oldPodSpec, newPodSpec)...)
+	return results, lv, nil
+}
+
+// runChecks evaluates checks against the pod, fanned out across a bounded worker pool so
+// admission scales with cores rather than with check count. Each check's result lands at its own
+// index in the pre-sized results slice, so ordering is deterministic despite the concurrency. When
+// r.options.MetricsRecorder is set, every check's duration and outcome is reported to it.
+func (r *checkRegistry) runChecks(level api.Level, version api.Version, checks []registeredCheck, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) []CheckResult {
+	if len(checks) == 0 {
+		return nil
+	}
+	results := make([]CheckResult, len(checks))
+	sem := make(chan struct{}, maxCheckConcurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for i, c := range checks {
+		sem <- struct{}{}
+		go func(i int, c registeredCheck) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			result := c.fn(podMetadata, podSpec)
+			if r.options.MetricsRecorder != nil {
+				r.options.MetricsRecorder.RecordCheck(c.id, level, version, time.Since(start), result.Allowed)
+			}
+			results[i] = result
+		}(i, c)
 	}
+	wg.Wait()
 	return results
 }
 
-func validateChecks(checks []LevelCheck) error {
-	ids := map[string]bool{}
+// runChecksForUpdate is runChecks' counterpart for a pod mutation: each check is evaluated via its
+// updateFn against the old and new pod if it registered one, or falls back to its fn against the
+// new pod alone.
+func (r *checkRegistry) runChecksForUpdate(level api.Level, version api.Version, checks []registeredCheck, oldPodMetadata, newPodMetadata *metav1.ObjectMeta, oldPodSpec, newPodSpec *corev1.PodSpec) []CheckResult {
+	if len(checks) == 0 {
+		return nil
+	}
+	results := make([]CheckResult, len(checks))
+	sem := make(chan struct{}, maxCheckConcurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+	for i, c := range checks {
+		sem <- struct{}{}
+		go func(i int, c registeredCheck) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			start := time.Now()
+			var result CheckResult
+			if c.updateFn != nil {
+				result = c.updateFn(oldPodMetadata, oldPodSpec, newPodMetadata, newPodSpec)
+			} else {
+				result = c.fn(newPodMetadata, newPodSpec)
+			}
+			if r.options.MetricsRecorder != nil {
+				r.options.MetricsRecorder.RecordCheck(c.id, level, version, time.Since(start), result.Allowed)
+			}
+			results[i] = result
+		}(i, c)
+	}
+	wg.Wait()
+	return results
+}
+
+// warningResultsForUpdate is warningResults' counterpart for a pod mutation.
+func (r *checkRegistry) warningResultsForUpdate(level api.Level, version api.Version, checks []registeredCheck, oldPodMetadata, newPodMetadata *metav1.ObjectMeta, oldPodSpec, newPodSpec *corev1.PodSpec) []CheckResult {
+	var results []CheckResult
+	for _, result := range r.runChecksForUpdate(level, version, checks, oldPodMetadata, newPodMetadata, oldPodSpec, newPodSpec) {
+		if result.Allowed {
+			continue
+		}
+		result.Allowed = true
+		result.Kind = CheckResultWarning
+		results = append(results, result)
+	}
+	return results
+}
+
+// warningResults runs checks that are still in their deprecation window and returns a
+// CheckResultWarning for each one that would currently forbid the pod, so callers can surface
+// "would fail at v1.X" hints without those checks affecting admission.
+func (r *checkRegistry) warningResults(level api.Level, version api.Version, checks []registeredCheck, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec) []CheckResult {
+	var results []CheckResult
+	for _, result := range r.runChecks(level, version, checks, podMetadata, podSpec) {
+		if result.Allowed {
+			continue
+		}
+		result.Allowed = true
+		result.Kind = CheckResultWarning
+		results = append(results, result)
+	}
+	return results
+}
+
+// CheckPodWithExemptions checks whether the pod is exempt per exemptions, short-circuiting to a
+// single CheckResultExempt result if so. Otherwise it delegates to CheckPod.
+func (r *checkRegistry) CheckPodWithExemptions(lv api.LevelVersion, podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, nsMetadata *metav1.ObjectMeta, exemptions Exemptions) ([]CheckResult, api.LevelVersion, error) {
+	if exemptions.exempt(nsMetadata, podMetadata, podSpec) {
+		return []CheckResult{{Allowed: true, Kind: CheckResultExempt}}, lv, nil
+	}
+	results, effective, err := r.CheckPod(lv, podMetadata, podSpec)
+	if err != nil {
+		return nil, effective, err
+	}
+	for i := range results {
+		if results[i].Kind == CheckResultWarning {
+			continue
+		}
+		if results[i].Allowed {
+			results[i].Kind = CheckResultCompliant
+		} else {
+			results[i].Kind = CheckResultForbidden
+		}
+	}
+	return results, effective, nil
+}
+
+// ChecksForLevelAndVersion returns the IDs of the checks CheckPod would run for lv. See the
+// Registry interface for details.
+func (r *checkRegistry) ChecksForLevelAndVersion(lv api.LevelVersion) []string {
+	if lv.Level == api.LevelPrivileged {
+		return nil
+	}
+	version := lv.Version
+	if r.maxVersion.Older(version) {
+		version = r.maxVersion
+	}
+	seen := map[string]bool{}
+	var ids []string
+	appendIDs := func(checks []registeredCheck) {
+		for _, c := range checks {
+			if !seen[c.id] {
+				seen[c.id] = true
+				ids = append(ids, c.id)
+			}
+		}
+	}
+	appendIDs(r.baselineChecks[version])
+	if lv.Level == api.LevelRestricted {
+		appendIDs(r.restrictedChecks[version])
+	}
+	return ids
+}
+
+// validateChecks validates checks, recording each check's ID into ids (shared across calls so
+// later registrations can detect collisions with earlier ones). When external is true, checks are
+// additionally required to use the namespaced external ID scheme, e.g. "example.com/no-hostpath".
+func validateChecks(checks []LevelCheck, ids map[string]bool, external bool) error {
 	for _, check := range checks {
 		if ids[check.ID] {
 			return fmt.Errorf("multiple checks registered for ID %s", check.ID)
 		}
+		if external {
+			if !externalIDPattern.MatchString(check.ID) {
+				return fmt.Errorf("check %s: external check IDs must be namespaced as <dns-domain>/<name>, e.g. example.com/no-hostpath", check.ID)
+			}
+		} else if strings.Contains(check.ID, "/") {
+			return fmt.Errorf("check %s: built-in check IDs must not contain '/'", check.ID)
+		}
 		ids[check.ID] = true
 		if check.Level != api.LevelBaseline && check.Level != api.LevelRestricted {
 			return fmt.Errorf("check %s: invalid level %s", check.ID, check.Level)
@@ -85,7 +434,7 @@ func validateChecks(checks []LevelCheck) error {
 			return fmt.Errorf("check %s: empty", check.ID)
 		}
 		maxVersion := api.Version{}
-		for _, c := range check.Versions {
+		for i, c := range check.Versions {
 			if c.MinimumVersion == "" {
 				return fmt.Errorf("check %s: undefined version found", check.ID)
 			}
@@ -100,6 +449,27 @@ func validateChecks(checks []LevelCheck) error {
 				return fmt.Errorf("check %s: versions must be strictly increasing", check.ID)
 			}
 			maxVersion = v
+			if c.RemovedInVersion != "" {
+				removed, err := api.VersionToEvaluate(c.RemovedInVersion)
+				if err != nil {
+					return fmt.Errorf("check %s: invalid removedInVersion %s: %v", check.ID, c.RemovedInVersion, err)
+				}
+				if !v.Older(removed) {
+					return fmt.Errorf("check %s: removedInVersion %s must be after minimumVersion %s", check.ID, c.RemovedInVersion, c.MinimumVersion)
+				}
+			}
+			if c.DeprecatedInVersion != "" {
+				if i+1 >= len(check.Versions) {
+					return fmt.Errorf("check %s: deprecatedInVersion set on last version %s with no successor to preview", check.ID, c.MinimumVersion)
+				}
+				deprecated, err := api.VersionToEvaluate(c.DeprecatedInVersion)
+				if err != nil {
+					return fmt.Errorf("check %s: invalid deprecatedInVersion %s: %v", check.ID, c.DeprecatedInVersion, err)
+				}
+				if deprecated.Older(v) {
+					return fmt.Errorf("check %s: deprecatedInVersion %s must not be before minimumVersion %s", check.ID, c.DeprecatedInVersion, c.MinimumVersion)
+				}
+			}
 		}
 	}
 	return nil
@@ -116,14 +486,14 @@ func populate(r *checkRegistry, validChecks []LevelCheck) {
 
 	for _, c := range validChecks {
 		if c.Level == api.LevelRestricted {
-			inflateVersions(c, r.restrictedChecks, r.maxVersion)
+			inflateVersions(c, r.restrictedChecks, r.restrictedWarningChecks, r.maxVersion)
 		} else {
-			inflateVersions(c, r.baselineChecks, r.maxVersion)
+			inflateVersions(c, r.baselineChecks, r.baselineWarningChecks, r.maxVersion)
 		}
 	}
 }
 
-func inflateVersions(check LevelCheck, versions map[api.Version][]Check, maxVersion api.Version) {
+func inflateVersions(check LevelCheck, versions, warningVersions map[api.Version][]registeredCheck, maxVersion api.Version) {
 	for i, c := range check.Versions {
 		var nextVersion api.Version
 		if i+1 < len(check.Versions) {
@@ -132,11 +502,32 @@ func inflateVersions(check LevelCheck, versions map[api.Version][]Check, maxVers
 			// Assumes only 1 Major version.
 			nextVersion = api.MajorMinorVersion(1, maxVersion.Minor()+1)
 		}
-		// Iterate over all versions from the minimum of the current check, to the minimum of the
-		// next check, or the maxVersion++.
+
+		// A check can sunset early, ahead of the next check taking over (or ahead of maxVersion++
+		// for the last check), via RemovedInVersion.
+		endVersion := nextVersion
+		if c.RemovedInVersion != "" {
+			if removedVersion, _ := api.VersionToEvaluate(c.RemovedInVersion); removedVersion.Older(endVersion) {
+				endVersion = removedVersion
+			}
+		}
+
+		// Iterate over all versions from the minimum of the current check, to endVersion.
 		minimumVersion, _ := api.VersionToEvaluate(c.MinimumVersion)
-		for v := minimumVersion; v.Older(nextVersion); v = api.MajorMinorVersion(1, v.Minor()+1) {
-			versions[v] = append(versions[v], check.Versions[i].CheckPod)
+		registered := registeredCheck{id: check.ID, fn: check.Versions[i].CheckPod, updateFn: check.Versions[i].CheckPodUpdate}
+		for v := minimumVersion; v.Older(endVersion); v = api.MajorMinorVersion(1, v.Minor()+1) {
+			versions[v] = append(versions[v], registered)
+		}
+
+		// If the next check is already scheduled and this one carries a DeprecatedInVersion,
+		// surface the next check early as a warning-tier preview, starting at DeprecatedInVersion
+		// and running until it actually takes effect at nextVersion.
+		if c.DeprecatedInVersion != "" && i+1 < len(check.Versions) {
+			deprecatedVersion, _ := api.VersionToEvaluate(c.DeprecatedInVersion)
+			nextRegistered := registeredCheck{id: check.ID, fn: check.Versions[i+1].CheckPod, updateFn: check.Versions[i+1].CheckPodUpdate}
+			for v := deprecatedVersion; v.Older(nextVersion); v = api.MajorMinorVersion(1, v.Minor()+1) {
+				warningVersions[v] = append(warningVersions[v], nextRegistered)
+			}
 		}
 	}
 }
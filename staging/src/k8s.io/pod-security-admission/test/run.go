@@ -47,6 +47,12 @@ type Options struct {
 	// If unset, policy.DefaultChecks() are used.
 	Checks []policy.LevelCheck
 
+	// WorkloadKinds are the pod-template-carrying kinds, beyond bare Pods, that every fixture is
+	// also wrapped in and dry-run-created through its corresponding typed client, exercising
+	// admission.DefaultPodSpecExtractor end-to-end for each. If unset, DefaultWorkloadKinds are
+	// used.
+	WorkloadKinds []WorkloadKind
+
 	// ExemptClient is an optional client interface to exercise behavior of an exempt client.
 	ExemptClient kubernetes.Interface
 	// ExemptNamespaces are optional namespaces not expected to have PodSecurity controls enforced.
@@ -110,7 +116,10 @@ func Run(t *testing.T, opts Options) {
 	if len(opts.Checks) == 0 {
 		opts.Checks = policy.DefaultChecks()
 	}
-	_, err := policy.NewCheckRegistry(opts.Checks)
+	if opts.WorkloadKinds == nil {
+		opts.WorkloadKinds = DefaultWorkloadKinds
+	}
+	_, err := policy.NewCheckRegistry(opts.Checks, policy.RegistryOptions{})
 	if err != nil {
 		t.Fatalf("invalid checks: %v", err)
 	}
@@ -185,6 +194,9 @@ func Run(t *testing.T, opts Options) {
 			}
 			t.Run(ns+"_pass_base", func(t *testing.T) {
 				createPod(t, 0, minimalValidPod.DeepCopy(), true, "")
+				for _, kind := range opts.WorkloadKinds {
+					createWorkload(t, opts.Client, ns, kind, 0, minimalValidPod.DeepCopy(), true, "")
+				}
 			})
 
 			checkIDs, err := checksForLevelAndVersion(opts.Checks, level, version)
@@ -203,11 +215,17 @@ func Run(t *testing.T, opts Options) {
 				t.Run(ns+"_pass_"+checkID, func(t *testing.T) {
 					for i, pod := range checkData.pass {
 						createPod(t, i, pod, true, "")
+						for _, kind := range opts.WorkloadKinds {
+							createWorkload(t, opts.Client, ns, kind, i, pod, true, "")
+						}
 					}
 				})
 				t.Run(ns+"_fail_"+checkID, func(t *testing.T) {
 					for i, pod := range checkData.fail {
 						createPod(t, i, pod, false, checkData.expectErrorSubstring)
+						for _, kind := range opts.WorkloadKinds {
+							createWorkload(t, opts.Client, ns, kind, i, pod, false, checkData.expectErrorSubstring)
+						}
 					}
 				})
 			}
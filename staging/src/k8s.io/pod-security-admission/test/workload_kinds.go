@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/pod-security-admission/policy"
+)
+
+// WorkloadKind identifies one of the pod-template-carrying kinds (beyond bare Pods) that
+// admission.DefaultPodSpecExtractor knows how to extract a PodSpec from.
+type WorkloadKind string
+
+const (
+	WorkloadKindPodTemplate           WorkloadKind = "PodTemplate"
+	WorkloadKindReplicationController WorkloadKind = "ReplicationController"
+	WorkloadKindReplicaSet            WorkloadKind = "ReplicaSet"
+	WorkloadKindDeployment            WorkloadKind = "Deployment"
+	WorkloadKindStatefulSet           WorkloadKind = "StatefulSet"
+	WorkloadKindDaemonSet             WorkloadKind = "DaemonSet"
+	WorkloadKindJob                   WorkloadKind = "Job"
+	WorkloadKindCronJob               WorkloadKind = "CronJob"
+)
+
+// DefaultWorkloadKinds are every kind DefaultPodSpecExtractor knows how to extract a PodSpec
+// from, beyond bare Pods. Used as Options.WorkloadKinds' default.
+var DefaultWorkloadKinds = []WorkloadKind{
+	WorkloadKindPodTemplate,
+	WorkloadKindReplicationController,
+	WorkloadKindReplicaSet,
+	WorkloadKindDeployment,
+	WorkloadKindStatefulSet,
+	WorkloadKindDaemonSet,
+	WorkloadKindJob,
+	WorkloadKindCronJob,
+}
+
+// createWorkload wraps pod's spec in the named kind and dry-run-creates it via the corresponding
+// typed client, applying the same pass/fail assertions createPod applies to bare Pods: a
+// violating pod spec must be rejected with expectErrorSubstring, and never with
+// policy.UnknownForbiddenReason.
+func createWorkload(t *testing.T, client kubernetes.Interface, ns string, kind WorkloadKind, i int, pod *corev1.Pod, expectSuccess bool, expectErrorSubstring string) {
+	t.Helper()
+	// avoid mutating original pod fixture
+	pod = pod.DeepCopy()
+	pod.Name = "test"
+	pod.Spec.ServiceAccountName = "default"
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name},
+		Spec:       pod.Spec,
+	}
+	dryRun := metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+	ctx := context.Background()
+
+	var err error
+	switch kind {
+	case WorkloadKindPodTemplate:
+		_, err = client.CoreV1().PodTemplates(ns).Create(ctx, &corev1.PodTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Template:   template,
+		}, dryRun)
+	case WorkloadKindReplicationController:
+		_, err = client.CoreV1().ReplicationControllers(ns).Create(ctx, &corev1.ReplicationController{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       corev1.ReplicationControllerSpec{Template: &template},
+		}, dryRun)
+	case WorkloadKindReplicaSet:
+		_, err = client.AppsV1().ReplicaSets(ns).Create(ctx, &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       appsv1.ReplicaSetSpec{Template: template},
+		}, dryRun)
+	case WorkloadKindDeployment:
+		_, err = client.AppsV1().Deployments(ns).Create(ctx, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       appsv1.DeploymentSpec{Template: template},
+		}, dryRun)
+	case WorkloadKindStatefulSet:
+		_, err = client.AppsV1().StatefulSets(ns).Create(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       appsv1.StatefulSetSpec{Template: template},
+		}, dryRun)
+	case WorkloadKindDaemonSet:
+		_, err = client.AppsV1().DaemonSets(ns).Create(ctx, &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       appsv1.DaemonSetSpec{Template: template},
+		}, dryRun)
+	case WorkloadKindJob:
+		_, err = client.BatchV1().Jobs(ns).Create(ctx, &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec:       batchv1.JobSpec{Template: template},
+		}, dryRun)
+	case WorkloadKindCronJob:
+		_, err = client.BatchV1().CronJobs(ns).Create(ctx, &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:    "* * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}},
+			},
+		}, dryRun)
+	default:
+		t.Fatalf("unknown workload kind %s", kind)
+		return
+	}
+
+	if !expectSuccess {
+		if err == nil {
+			t.Errorf("%d: expected error creating %s %s, got none", i, kind, toJSON(pod))
+			return
+		}
+		if strings.Contains(err.Error(), policy.UnknownForbiddenReason) {
+			t.Errorf("%d: unexpected unknown forbidden reason creating %s %s: %v", i, kind, toJSON(pod), err)
+		}
+		if !strings.Contains(err.Error(), expectErrorSubstring) {
+			t.Errorf("%d: expected error with substring %q creating %s %s, got %v", i, expectErrorSubstring, kind, toJSON(pod), err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("%d: unexpected error creating %s %s: %v", i, kind, toJSON(pod), err)
+	}
+}
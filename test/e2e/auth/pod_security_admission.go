@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/pod-security-admission/api"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	. "github.com/onsi/ginkgo"
+)
+
+// SIGDescribe("PodSecurityAdmission", ...) is the PodSecurity-admission counterpart to the
+// PodSecurityPolicy suite in pod_security_policy.go: it drives the same battery of privileged/hostpath/hostnet/
+// hostpid/hostipc/seccomp/apparmor/CAP_SYS_ADMIN pods from testPrivilegedPods, but through
+// namespace labels instead of a PodSecurityPolicy object, so both admission paths are exercised
+// against one shared set of "is this pod privileged" fixtures.
+var _ = SIGDescribe("PodSecurityAdmission", func() {
+	f := framework.NewDefaultFramework("podsecurityadmission")
+
+	BeforeEach(func() {
+		if !framework.IsPodSecurityAdmissionEnabled(f) {
+			framework.Skipf("PodSecurity admission not enabled")
+		}
+	})
+
+	enforceCase := func(level api.Level, version api.Version) {
+		ns := f.Namespace.Name
+		framework.EnsurePodSecurityLabels(f, ns, level, "", "", version)
+
+		testPrivilegedPods(f, func(pod *v1.Pod) {
+			created, err := f.ClientSet.Core().Pods(ns).Create(pod)
+			if pod.Name == "apparmor" {
+				// Unlike restrictivePSPTemplate in pod_security_policy.go, PSA's AppArmor control only rejects
+				// profiles outside "runtime/default"/"localhost/*"; this fixture's
+				// "localhost/docker-default" profile is compliant at every PSA level.
+				framework.ExpectNoError(err, "localhost/ AppArmor profiles should be allowed")
+				framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(f.ClientSet, created.Name, created.Namespace))
+				return
+			}
+			// Every other fixture in testPrivilegedPods requests a host namespace, a hostPath
+			// volume, a non-default capability, privileged mode, or an Unconfined seccomp profile, all of
+			// which baseline (and therefore restricted, which is a strict superset) forbids.
+			expectForbidden(err)
+		})
+	}
+
+	It("should enforce the baseline policy at the latest version", func() {
+		enforceCase(api.LevelBaseline, api.LatestVersion())
+	})
+
+	It("should enforce the restricted policy at the latest version", func() {
+		enforceCase(api.LevelRestricted, api.LatestVersion())
+	})
+
+	It("should allow pods under the privileged policy", func() {
+		ns := f.Namespace.Name
+		framework.EnsurePodSecurityLabels(f, ns, api.LevelPrivileged, "", "", api.LatestVersion())
+
+		testPrivilegedPods(f, func(pod *v1.Pod) {
+			p, err := f.ClientSet.Core().Pods(ns).Create(pod)
+			framework.ExpectNoError(err)
+			framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(f.ClientSet, p.Name, p.Namespace))
+		})
+	})
+
+	// The versioned check registry only started forbidding an Unconfined seccomp profile at
+	// v1.19 (https://github.com/kubernetes/enhancements/tree/master/keps/sig-auth/2579-pod-security-admission),
+	// so a pod relying on it should pass against an old pinned enforce-version and only start
+	// failing once the namespace tracks "latest".
+	It("should only forbid Unconfined seccomp once the enforce-version reaches v1.19", func() {
+		ns := f.Namespace.Name
+		unconfined := restrictedPod(f, "seccomp-old-version")
+		unconfined.Annotations[v1.SeccompPodAnnotationKey] = "unconfined"
+
+		By("Pinning enforce-version to v1.10, before the seccomp check existed")
+		framework.EnsurePodSecurityLabels(f, ns, api.LevelBaseline, "", "", api.MajorMinorVersion(1, 10))
+		allowed, err := f.ClientSet.Core().Pods(ns).Create(unconfined)
+		framework.ExpectNoError(err, "Unconfined seccomp should be allowed at v1.10")
+		framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(f.ClientSet, allowed.Name, allowed.Namespace))
+		framework.ExpectNoError(f.ClientSet.Core().Pods(ns).Delete(allowed.Name, &metav1.DeleteOptions{}))
+
+		By("Advancing enforce-version to latest")
+		framework.EnsurePodSecurityLabels(f, ns, api.LevelBaseline, "", "", api.LatestVersion())
+		_, err = f.ClientSet.Core().Pods(ns).Create(unconfined)
+		expectForbidden(err)
+	})
+
+	// warnPodSubstrings maps a testPrivilegedPods fixture name to a substring its PodSecurity
+	// warning message is expected to contain, mirroring the pattern used in enforceCase above to
+	// special-case the compliant AppArmor fixture.
+	warnPodSubstrings := map[string]string{
+		"privileged": "privileged",
+		"hostpath":   "hostPath",
+		"hostnet":    "hostNetwork",
+		"hostpid":    "hostPID",
+		"hostipc":    "hostIPC",
+		"seccomp":    "seccomp",
+		"sysadmin":   "SYS_ADMIN",
+	}
+
+	It("should warn, not reject, enforce=privileged pods that violate warn=restricted", func() {
+		ns := f.Namespace.Name
+		framework.EnsurePodSecurityLabels(f, ns, api.LevelPrivileged, "", api.LevelRestricted, api.LatestVersion())
+		c, warnings := newWarningRecordingClient(f)
+
+		testPrivilegedPods(f, func(pod *v1.Pod) {
+			wantSubstring, exercisesWarnMode := warnPodSubstrings[pod.Name]
+			if !exercisesWarnMode {
+				// The "apparmor" fixture is compliant at every PSA level, so it produces no
+				// warning to assert on here; enforceCase above already covers it.
+				return
+			}
+			p, err := c.Core().Pods(ns).Create(pod)
+			expectWarn(err, warnings.drain(), wantSubstring)
+			framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(c, p.Name, p.Namespace))
+		})
+	})
+})
@@ -25,6 +25,7 @@ import (
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/apiserver/pkg/authentication/serviceaccount"
 	clientset "k8s.io/client-go/kubernetes"
@@ -169,6 +170,10 @@ var _ = SIGDescribe("PodSecurityPolicy", func() {
 			_, err := c.Core().Pods(ns).Create(pod)
 			expectForbidden(err)
 		})
+
+		testPrivilegedEphemeralContainer(f, c, func(pod *v1.Pod, err error) {
+			expectForbidden(err)
+		})
 	})
 
 	It("should allow pods under the privileged PodSecurityPolicy", func() {
@@ -180,6 +185,11 @@ var _ = SIGDescribe("PodSecurityPolicy", func() {
 			framework.ExpectNoError(err)
 			framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(c, p.Name, p.Namespace))
 		})
+
+		testPrivilegedEphemeralContainer(f, c, func(pod *v1.Pod, err error) {
+			framework.ExpectNoError(err)
+			framework.ExpectNoError(waitForContainerStarted(c, pod.Namespace, pod.Name, "debugger"))
+		})
 	})
 })
 
@@ -188,69 +198,137 @@ func expectForbidden(err error) {
 	Expect(apierrs.IsForbidden(err)).To(BeTrue(), "should be forbidden error")
 }
 
-func testPrivilegedPods(f *framework.Framework, tester func(pod *v1.Pod)) {
-	By("Running a privileged pod", func() {
-		privileged := restrictedPod(f, "privileged")
-		privileged.Spec.Containers[0].SecurityContext.Privileged = boolPtr(true)
-		privileged.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
-		tester(privileged)
-	})
-
-	By("Running a HostPath pod", func() {
-		hostpath := restrictedPod(f, "hostpath")
-		hostpath.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{
-			Name:      "hp",
-			MountPath: "/hp",
-		}}
-		hostpath.Spec.Volumes = []v1.Volume{{
-			Name: "hp",
-			VolumeSource: v1.VolumeSource{
-				HostPath: &v1.HostPathVolumeSource{Path: "/tmp"},
-			},
-		}}
-		tester(hostpath)
-	})
+// privilegedPodFixture is one "should be forbidden under a restrictive policy, allowed under a
+// permissive one" pod, shared by testPrivilegedPods and the PSP/PSA equivalence test in
+// psp_psa_equivalence.go. field
+// names the aspect of the pod spec it violates (host*, caps, seccomp, apparmor, runAsUser, ...),
+// for tests that need to report which field caused a divergence.
+type privilegedPodFixture struct {
+	name        string
+	description string
+	field       string
+	mutate      func(pod *v1.Pod)
+}
 
-	By("Running a HostNetwork pod", func() {
-		hostnet := restrictedPod(f, "hostnet")
-		hostnet.Spec.HostNetwork = true
-		tester(hostnet)
-	})
+var privilegedPodFixtures = []privilegedPodFixture{
+	{
+		name: "privileged", description: "Running a privileged pod", field: "privileged",
+		mutate: func(pod *v1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.Privileged = boolPtr(true)
+			pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
+		},
+	},
+	{
+		name: "hostpath", description: "Running a HostPath pod", field: "hostPath volumes",
+		mutate: func(pod *v1.Pod) {
+			pod.Spec.Containers[0].VolumeMounts = []v1.VolumeMount{{
+				Name:      "hp",
+				MountPath: "/hp",
+			}}
+			pod.Spec.Volumes = []v1.Volume{{
+				Name: "hp",
+				VolumeSource: v1.VolumeSource{
+					HostPath: &v1.HostPathVolumeSource{Path: "/tmp"},
+				},
+			}}
+		},
+	},
+	{
+		name: "hostnet", description: "Running a HostNetwork pod", field: "hostNetwork",
+		mutate: func(pod *v1.Pod) { pod.Spec.HostNetwork = true },
+	},
+	{
+		name: "hostpid", description: "Running a HostPID pod", field: "hostPID",
+		mutate: func(pod *v1.Pod) { pod.Spec.HostPID = true },
+	},
+	{
+		name: "hostipc", description: "Running a HostIPC pod", field: "hostIPC",
+		mutate: func(pod *v1.Pod) { pod.Spec.HostIPC = true },
+	},
+	{
+		name: "apparmor", description: "Running a custom AppArmor profile pod", field: "apparmor",
+		mutate: func(pod *v1.Pod) {
+			// Every node is expected to have the docker-default profile.
+			pod.Annotations[apparmor.ContainerAnnotationKeyPrefix+"pause"] = "localhost/docker-default"
+		},
+	},
+	{
+		name: "seccomp", description: "Running an unconfined Seccomp pod", field: "seccomp",
+		mutate: func(pod *v1.Pod) { pod.Annotations[v1.SeccompPodAnnotationKey] = "unconfined" },
+	},
+	{
+		name: "sysadmin", description: "Running a CAP_SYS_ADMIN pod", field: "capabilities",
+		mutate: func(pod *v1.Pod) {
+			pod.Spec.Containers[0].SecurityContext.Capabilities = &v1.Capabilities{
+				Add: []v1.Capability{"CAP_SYS_ADMIN"},
+			}
+			pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
+		},
+	},
+}
 
-	By("Running a HostPID pod", func() {
-		hostpid := restrictedPod(f, "hostpid")
-		hostpid.Spec.HostPID = true
-		tester(hostpid)
-	})
+func testPrivilegedPods(f *framework.Framework, tester func(pod *v1.Pod)) {
+	for _, fixture := range privilegedPodFixtures {
+		if fixture.name == "apparmor" && !common.IsAppArmorSupported() {
+			continue
+		}
+		fixture := fixture
+		By(fixture.description, func() {
+			pod := restrictedPod(f, fixture.name)
+			fixture.mutate(pod)
+			tester(pod)
+		})
+	}
+}
 
-	By("Running a HostIPC pod", func() {
-		hostipc := restrictedPod(f, "hostipc")
-		hostipc.Spec.HostIPC = true
-		tester(hostipc)
-	})
+// testPrivilegedEphemeralContainer creates a compliant restrictedPod, waits for it to run, then
+// adds a privileged debug container through the EphemeralContainers subresource instead of pod
+// creation. testPrivilegedPods only ever mutates the pod spec before Create, so it never
+// exercises this path; recent CVEs have shown that admission checks applied only at pod-create
+// time can be bypassed by a later ephemeral-container update. tester receives the update error
+// (and the pod it was attempted against) so the caller can assert forbidden or allowed, the same
+// way testPrivilegedPods callers do.
+func testPrivilegedEphemeralContainer(f *framework.Framework, c clientset.Interface, tester func(pod *v1.Pod, err error)) {
+	By("Injecting a privileged ephemeral container", func() {
+		ns := f.Namespace.Name
+		pod, err := c.Core().Pods(ns).Create(restrictedPod(f, "ephemeral-base"))
+		framework.ExpectNoError(err)
+		framework.ExpectNoError(framework.WaitForPodNameRunningInNamespace(c, pod.Name, pod.Namespace))
 
-	if common.IsAppArmorSupported() {
-		By("Running a custom AppArmor profile pod", func() {
-			aa := restrictedPod(f, "apparmor")
-			// Every node is expected to have the docker-default profile.
-			aa.Annotations[apparmor.ContainerAnnotationKeyPrefix+"pause"] = "localhost/docker-default"
-			tester(aa)
+		pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{
+				Name:  "debugger",
+				Image: framework.GetPauseImageName(f.ClientSet),
+				SecurityContext: &v1.SecurityContext{
+					Privileged: boolPtr(true),
+					Capabilities: &v1.Capabilities{
+						Add: []v1.Capability{"CAP_SYS_ADMIN"},
+					},
+				},
+			},
 		})
-	}
+		pod.Annotations[v1.SeccompPodAnnotationKey] = "unconfined"
 
-	By("Running an unconfined Seccomp pod", func() {
-		unconfined := restrictedPod(f, "seccomp")
-		unconfined.Annotations[v1.SeccompPodAnnotationKey] = "unconfined"
-		tester(unconfined)
+		updated, err := c.Core().Pods(ns).UpdateEphemeralContainers(pod)
+		tester(updated, err)
 	})
+}
 
-	By("Running a CAP_SYS_ADMIN pod", func() {
-		sysadmin := restrictedPod(f, "sysadmin")
-		sysadmin.Spec.Containers[0].SecurityContext.Capabilities = &v1.Capabilities{
-			Add: []v1.Capability{"CAP_SYS_ADMIN"},
+// waitForContainerStarted polls the pod's EphemeralContainerStatuses until containerName reports
+// a Running state, confirming the ephemeral container admitted above actually started rather than
+// only being accepted by the API server.
+func waitForContainerStarted(c clientset.Interface, ns, podName, containerName string) error {
+	return wait.PollImmediate(framework.Poll, framework.PodStartTimeout, func() (bool, error) {
+		pod, err := c.Core().Pods(ns).Get(podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, status := range pod.Status.EphemeralContainerStatuses {
+			if status.Name == containerName {
+				return status.State.Running != nil, nil
+			}
 		}
-		sysadmin.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = nil
-		tester(sysadmin)
+		return false, nil
 	})
 }
 
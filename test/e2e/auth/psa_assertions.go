@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	. "github.com/onsi/gomega"
+)
+
+// violationAnnotationKey returns the pod-security.kubernetes.io/{mode}-violations annotation key
+// PSA stamps on the audit event (mode "audit") or returns as part of an API warning (mode "warn")
+// when a pod fails rules evaluated in that mode, as opposed to "enforce", which rejects the
+// request outright.
+func violationAnnotationKey(mode string) string {
+	return fmt.Sprintf("pod-security.kubernetes.io/%s-violations", mode)
+}
+
+// expectWarn asserts that an otherwise-successful pod creation (err == nil, as PSA's warn mode
+// never rejects a request) surfaced at least one API warning whose message contains wantSubstr.
+func expectWarn(err error, warnings []string, wantSubstr string) {
+	framework.ExpectNoError(err, "pod should be admitted under warn mode")
+	for _, w := range warnings {
+		if strings.Contains(w, wantSubstr) {
+			return
+		}
+	}
+	Fail(fmt.Sprintf("expected a PodSecurity warning containing %q, got %v", wantSubstr, warnings))
+}
+
+// expectAuditAnnotation asserts that auditAnnotations -- the annotations PSA's audit mode stamped
+// onto this request's audit event, under violationAnnotationKey("audit") -- record a violation
+// whose value contains wantSubstr.
+//
+// Note: this e2e suite has no audit log sink wired up (test/e2e/framework carries no
+// TestContext.AuditLogFile equivalent in this tree), so nothing in this package currently produces
+// an auditAnnotations map to pass in; a caller with access to the apiserver's audit log would read
+// the event for this request's UID and extract its Annotations.
+func expectAuditAnnotation(auditAnnotations map[string]string, key, wantSubstr string) {
+	Expect(auditAnnotations).To(HaveKey(key))
+	Expect(auditAnnotations[key]).To(ContainSubstring(wantSubstr))
+}
+
+// warningRecorder implements rest.WarningHandler, collecting every API warning header seen by a
+// client so e2e assertions can inspect them with expectWarn.
+type warningRecorder struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (w *warningRecorder) HandleWarningHeader(code int, agent string, message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.warnings = append(w.warnings, message)
+}
+
+// drain returns every warning recorded since the last drain and resets the recorder, so
+// consecutive requests on the same client don't leak warnings into each other's assertions.
+func (w *warningRecorder) drain() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	warnings := w.warnings
+	w.warnings = nil
+	return warnings
+}
+
+// newWarningRecordingClient returns a client identical to f.ClientSet, except its API warnings are
+// captured by the returned warningRecorder instead of being logged, so tests can assert on them.
+func newWarningRecordingClient(f *framework.Framework) (clientset.Interface, *warningRecorder) {
+	config, err := framework.LoadConfig()
+	framework.ExpectNoError(err)
+	recorder := &warningRecorder{}
+	config.WarningHandler = recorder
+	c, err := clientset.NewForConfig(config)
+	framework.ExpectNoError(err)
+	return c, recorder
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/pod-security-admission/api"
+	"k8s.io/pod-security-admission/policy"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// pspFieldForCheckID tightens template toward the PodSecurityPolicy-equivalent of a single
+// versioned check. Unrecognized IDs (a check added to the registry that this generator hasn't
+// been taught about yet) are left alone rather than erroring, so a version bump degrades to an
+// under-restrictive PSP instead of a broken one; callers should still watch framework.Logf output
+// for unrecognized IDs after a registry upgrade.
+func pspFieldForCheckID(id string, template *extensionsv1beta1.PodSecurityPolicy) bool {
+	switch id {
+	case "privileged":
+		template.Spec.Privileged = false
+	case "hostNamespaces":
+		template.Spec.HostNetwork = false
+		template.Spec.HostIPC = false
+		template.Spec.HostPID = false
+	case "hostPorts":
+		template.Spec.HostPorts = nil
+	case "hostPathVolumes":
+		template.Spec.Volumes = removeVolumeType(template.Spec.Volumes, extensionsv1beta1.HostPath)
+	case "volumes":
+		template.Spec.Volumes = []extensionsv1beta1.FSType{
+			extensionsv1beta1.ConfigMap,
+			extensionsv1beta1.EmptyDir,
+			extensionsv1beta1.PersistentVolumeClaim,
+			extensionsv1beta1.Projected,
+			extensionsv1beta1.Secret,
+		}
+	case "capabilities":
+		template.Spec.AllowedCapabilities = nil
+		template.Spec.RequiredDropCapabilities = []extensionsv1beta1.Capability{
+			"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID",
+			"KILL", "MKNOD", "NET_RAW", "SETGID", "SETUID", "SYS_CHROOT",
+		}
+	case "allowPrivilegeEscalation":
+		allowEscalation := false
+		template.Spec.AllowPrivilegeEscalation = &allowEscalation
+	case "runAsUser", "runAsNonRoot":
+		template.Spec.RunAsUser.Rule = extensionsv1beta1.RunAsUserStrategyMustRunAsNonRoot
+	case "seccompProfile":
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations["seccomp.security.alpha.kubernetes.io/allowedProfileNames"] = "docker/default"
+		template.Annotations["seccomp.security.alpha.kubernetes.io/defaultProfileName"] = "docker/default"
+	case "appArmorProfile":
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations["apparmor.security.beta.kubernetes.io/allowedProfileNames"] = "runtime/default"
+		template.Annotations["apparmor.security.beta.kubernetes.io/defaultProfileName"] = "runtime/default"
+	case "readOnlyRootFilesystem":
+		template.Spec.ReadOnlyRootFilesystem = true
+	default:
+		return false
+	}
+	return true
+}
+
+func removeVolumeType(volumes []extensionsv1beta1.FSType, remove extensionsv1beta1.FSType) []extensionsv1beta1.FSType {
+	kept := volumes[:0]
+	for _, v := range volumes {
+		if v != remove {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// generatePSPFromChecks synthesizes a PodSecurityPolicy for level/version by starting from a
+// fully-permissive template and tightening it field-by-field for every check ID
+// registry.ChecksForLevelAndVersion(api.LevelVersion{Level: level, Version: version}) returns.
+// This keeps PSP e2e coverage in lockstep with the authoritative PSA check set as new versions add
+// checks, rather than hand-maintaining permissivePSPTemplate/restrictivePSPTemplate YAML that
+// drifts out of sync with pkg/policy. name is used as the PSP's ObjectMeta.Name.
+func generatePSPFromChecks(registry policy.Registry, level api.Level, version api.Version, name string) *extensionsv1beta1.PodSecurityPolicy {
+	template := &extensionsv1beta1.PodSecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: extensionsv1beta1.PodSecurityPolicySpec{
+			Privileged:               true,
+			AllowPrivilegeEscalation: boolPtr(true),
+			AllowedCapabilities:      []extensionsv1beta1.Capability{"*"},
+			Volumes:                  []extensionsv1beta1.FSType{extensionsv1beta1.All},
+			HostNetwork:              true,
+			HostPorts:                []extensionsv1beta1.HostPortRange{{Min: 0, Max: 65535}},
+			HostIPC:                  true,
+			HostPID:                  true,
+			RunAsUser:                extensionsv1beta1.RunAsUserStrategyOptions{Rule: extensionsv1beta1.RunAsUserStrategyRunAsAny},
+			SELinux:                  extensionsv1beta1.SELinuxStrategyOptions{Rule: extensionsv1beta1.SELinuxStrategyRunAsAny},
+			SupplementalGroups:       extensionsv1beta1.SupplementalGroupsStrategyOptions{Rule: extensionsv1beta1.SupplementalGroupsStrategyRunAsAny},
+			FSGroup:                  extensionsv1beta1.FSGroupStrategyOptions{Rule: extensionsv1beta1.FSGroupStrategyRunAsAny},
+		},
+	}
+
+	for _, id := range registry.ChecksForLevelAndVersion(api.LevelVersion{Level: level, Version: version}) {
+		if !pspFieldForCheckID(id, template) {
+			framework.Logf("generatePSPFromChecks: no PSP field mapping for check %q; PSP will be more permissive than the %s policy at %s", id, level, version)
+		}
+	}
+	return template
+}
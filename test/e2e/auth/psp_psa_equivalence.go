@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1beta1 "k8s.io/api/rbac/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/pod-security-admission/api"
+
+	"k8s.io/kubernetes/test/e2e/common"
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// baselinePodFixture is the compliant control in the equivalence matrix below: it adds no
+// privilegedPodFixtures entry of its own because restrictedPod(f, name) is already compliant on
+// its own, with no mutate step needed.
+var baselinePodFixture = privilegedPodFixture{
+	name:        "baseline",
+	description: "Running a fully compliant pod",
+	field:       "(none)",
+	mutate:      func(pod *v1.Pod) {},
+}
+
+// SIGDescribe("PodSecurityPolicy/PodSecurityAdmission equivalence", ...) is a migration oracle: for
+// every fixture testPrivilegedPods exercises (plus the compliant baseline), it submits the same
+// pod to a namespace gated only by a bound, restrictive PodSecurityPolicy and to a namespace gated
+// only by an equivalent `pod-security.kubernetes.io/enforce=restricted` label, and fails if the two
+// admission decisions disagree. This gives operators a concrete, runnable check for the PSP-to-PSA
+// migration story instead of trusting a hand-written equivalence table.
+var _ = SIGDescribe("PodSecurityPolicy/PodSecurityAdmission equivalence", func() {
+	f := framework.NewDefaultFramework("psp-psa-equivalence")
+	f.SkipDefaultPSPBinding = true
+
+	BeforeEach(func() {
+		if !framework.IsPodSecurityPolicyEnabled(f) {
+			framework.Skipf("PodSecurityPolicy not enabled")
+		}
+		if !framework.IsRBACEnabled(f) {
+			framework.Skipf("RBAC not enabled")
+		}
+	})
+
+	It("should reach the same admission decision under an equivalent PodSecurityPolicy and PodSecurity admission level", func() {
+		pspNS := f.Namespace.Name
+
+		By("Creating a kubernetes client that impersonates the default service account")
+		config, err := framework.LoadConfig()
+		framework.ExpectNoError(err)
+		config.Impersonate = restclient.ImpersonationConfig{
+			UserName: serviceaccount.MakeUsername(pspNS, "default"),
+			Groups:   serviceaccount.MakeGroupNames(pspNS),
+		}
+		pspClient, err := clientset.NewForConfig(config)
+		framework.ExpectNoError(err)
+
+		By("Binding the edit role to the default SA")
+		framework.BindClusterRole(f.ClientSet.RbacV1beta1(), "edit", pspNS,
+			rbacv1beta1.Subject{Kind: rbacv1beta1.ServiceAccountKind, Namespace: pspNS, Name: "default"})
+
+		By("Creating & Binding a restricted policy for the test service account")
+		createAndBindPSP(f, restrictivePSPTemplate)
+
+		By("Creating a namespace gated by an equivalent PodSecurity admission level")
+		psaNamespace, err := f.ClientSet.CoreV1().Namespaces().Create(context.TODO(), &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: fmt.Sprintf("%s-psa-", pspNS)},
+		}, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		defer f.ClientSet.CoreV1().Namespaces().Delete(context.TODO(), psaNamespace.Name, metav1.DeleteOptions{})
+		psaNS := psaNamespace.Name
+		framework.EnsurePodSecurityLabels(f, psaNS, api.LevelRestricted, "", "", api.LatestVersion())
+
+		fixtures := append([]privilegedPodFixture{baselinePodFixture}, privilegedPodFixtures...)
+
+		var divergences []string
+		for _, fixture := range fixtures {
+			if fixture.name == "apparmor" && !common.IsAppArmorSupported() {
+				continue
+			}
+			By(fmt.Sprintf("Comparing admission of %q between PSP and PodSecurity admission", fixture.name), func() {
+				pspPod := restrictedPod(f, fixture.name)
+				fixture.mutate(pspPod)
+				_, pspErr := pspClient.Core().Pods(pspNS).Create(pspPod)
+
+				psaPod := restrictedPod(f, fixture.name)
+				fixture.mutate(psaPod)
+				_, psaErr := f.ClientSet.Core().Pods(psaNS).Create(psaPod)
+
+				pspForbidden, psaForbidden := isForbidden(pspErr), isForbidden(psaErr)
+				if pspForbidden != psaForbidden {
+					divergences = append(divergences, fmt.Sprintf(
+						"%s (field %s): PodSecurityPolicy forbade=%v, PodSecurity admission forbade=%v",
+						fixture.name, fixture.field, pspForbidden, psaForbidden))
+				}
+			})
+		}
+
+		Expect(divergences).To(BeEmpty(), "PodSecurityPolicy and PodSecurity admission disagreed:\n%s", strings.Join(divergences, "\n"))
+	})
+})
+
+// isForbidden reports whether err is a Forbidden API error, without failing the test the way
+// expectForbidden does; the equivalence test above needs to compare, not assert, forbidden-ness.
+func isForbidden(err error) bool {
+	return err != nil && apierrs.IsForbidden(err)
+}
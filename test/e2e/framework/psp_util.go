@@ -17,16 +17,24 @@ limitations under the License.
 package framework
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 
-	rbacv1beta1 "k8s.io/api/rbac/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/pod-security-admission/api"
 
 	. "github.com/onsi/ginkgo"
 )
 
 const (
+	// Deprecated: PodSecurityPolicy was removed from Kubernetes; these names are kept only so
+	// existing suites referencing them still compile.
 	PodSecurityPolicyPrivileged     = "gce.privileged"
 	PodSecurityPolicyPrivilegedRole = "gce:podsecuritypolicy:privileged"
 )
@@ -36,32 +44,91 @@ var (
 	isPSPEnabled     bool
 )
 
+// IsPodSecurityPolicyEnabled always returns false: the PodSecurityPolicy API was removed and no
+// longer exists on modern clusters.
+//
+// Deprecated: use IsPodSecurityAdmissionEnabled instead.
 func IsPodSecurityPolicyEnabled(f *Framework) bool {
 	isPSPEnabledOnce.Do(func() {
-		psps, err := f.ClientSet.ExtensionsV1beta1().PodSecurityPolicies().List(metav1.ListOptions{})
-		if err != nil {
-			Logf("Error listing PodSecurityPolicies; assuming PodSecurityPolicy is disabled: %v", err)
-			isPSPEnabled = false
-		} else if psps == nil || len(psps.Items) == 0 {
-			Logf("No PodSecurityPolicies found; assuming PodSecurityPolicy is disabled.")
-			isPSPEnabled = false
-		} else {
-			Logf("Found PodSecurityPolicies; assuming PodSecurityPolicy is enabled.")
-			isPSPEnabled = true
-		}
+		Logf("PodSecurityPolicy was removed from Kubernetes; IsPodSecurityPolicyEnabled always returns false. Use IsPodSecurityAdmissionEnabled instead.")
+		isPSPEnabled = false
 	})
 	return isPSPEnabled
 }
 
+// CreateDefaultPSPBinding is a no-op: the PodSecurityPolicy API was removed and no longer exists
+// on modern clusters.
+//
+// Deprecated: use EnsurePodSecurityLabels instead.
 func CreateDefaultPSPBinding(f *Framework, namespace string) {
-	By(fmt.Sprintf("Binding the %s PodSecurityPolicy to the default service account in %s",
-		PodSecurityPolicyPrivileged, namespace))
-	BindClusterRoleInNamespace(f.ClientSet.RbacV1beta1(),
-		PodSecurityPolicyPrivilegedRole,
-		namespace,
-		rbacv1beta1.Subject{
-			Kind:      rbacv1beta1.ServiceAccountKind,
-			Namespace: namespace,
-			Name:      "default",
-		})
+	Logf("PodSecurityPolicy was removed from Kubernetes; CreateDefaultPSPBinding is a no-op. Use EnsurePodSecurityLabels instead.")
+}
+
+// EnsurePodSecurityLabels labels namespace ns with the given enforce/audit/warn levels and
+// version, configuring Pod Security admission for it. version is written to all three "-version"
+// labels; a zero-value api.Version omits them, which evaluates at the newest version the apiserver
+// knows.
+func EnsurePodSecurityLabels(f *Framework, ns string, enforce, audit, warn api.Level, version api.Version) {
+	By(fmt.Sprintf("Labeling namespace %s for Pod Security admission: enforce=%s audit=%s warn=%s version=%s",
+		ns, enforce, audit, warn, version))
+
+	labels := map[string]string{
+		api.EnforceLevelLabel: string(enforce),
+		api.AuditLevelLabel:   string(audit),
+		api.WarnLevelLabel:    string(warn),
+	}
+	if version != (api.Version{}) {
+		v := version.String()
+		labels[api.EnforceVersionLabel] = v
+		labels[api.AuditVersionLabel] = v
+		labels[api.WarnVersionLabel] = v
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	ExpectNoError(err, "marshaling Pod Security labels for namespace %s", ns)
+	_, err = f.ClientSet.CoreV1().Namespaces().Patch(context.TODO(), ns, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	ExpectNoError(err, "labeling namespace %s for Pod Security admission", ns)
+}
+
+// IsPodSecurityAdmissionEnabled probes whether Pod Security admission is enforcing on this
+// cluster: it dry-run-creates a minimal pod with a `restricted`-violating spec in a
+// `restricted`-labeled namespace and checks whether the rejection names a PodSecurity violation.
+func IsPodSecurityAdmissionEnabled(f *Framework) bool {
+	probeNS := fmt.Sprintf("%s-psa-probe", f.Namespace.Name)
+	ns, err := f.ClientSet.CoreV1().Namespaces().Create(context.TODO(), &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   probeNS,
+			Labels: map[string]string{api.EnforceLevelLabel: string(api.LevelRestricted)},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		Logf("Error creating Pod Security probe namespace; assuming Pod Security admission is disabled: %v", err)
+		return false
+	}
+	defer f.ClientSet.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{})
+
+	privileged := true
+	_, err = f.ClientSet.CoreV1().Pods(ns.Name).Create(context.TODO(), &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "psa-probe"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:            "probe",
+				Image:           "registry.k8s.io/pause:3.9",
+				SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+	}, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+
+	if err == nil {
+		Logf("Privileged probe pod was admitted; assuming Pod Security admission is disabled.")
+		return false
+	}
+	if !apierrors.IsForbidden(err) || !strings.Contains(err.Error(), "PodSecurity") {
+		Logf("Probe pod was rejected for a reason other than a PodSecurity violation; assuming Pod Security admission is disabled: %v", err)
+		return false
+	}
+	Logf("Probe pod was rejected for a PodSecurity violation; Pod Security admission is enabled.")
+	return true
 }
@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wait
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	e2elog "k8s.io/kubernetes/test/e2e/framework/log"
+)
+
+// readyCheck reports whether obj is ready, plus a human-readable reason when it isn't.
+type readyCheck func(obj runtime.Object) (ready bool, reason string, err error)
+
+var (
+	readyChecksMu     sync.RWMutex
+	readyChecksByGVK  = map[schema.GroupVersionKind]readyCheck{}
+	readyChecksByType = map[reflect.Type]readyCheck{}
+)
+
+// RegisterReadyCheck registers check as the readiness predicate used by ForReady and ForRollout
+// for objects of Go type T. ForReady and ForRollout look checks up by T alone; gvk is recorded but
+// not consulted, so registering a second check for the same T replaces the first whatever its
+// gvk. Built-in checks for Deployments, DaemonSets, StatefulSets, Jobs, PersistentVolumeClaims,
+// Services, and Pods are registered below; downstream suites can call this to add checks for
+// CRDs, or to override a built-in check.
+func RegisterReadyCheck[T runtime.Object](gvk schema.GroupVersionKind, check func(T) (bool, string, error)) {
+	wrapped := func(obj runtime.Object) (bool, string, error) {
+		typed, ok := obj.(T)
+		if !ok {
+			return false, "", fmt.Errorf("ready check for %s got unexpected type %T", gvk, obj)
+		}
+		return check(typed)
+	}
+
+	readyChecksMu.Lock()
+	defer readyChecksMu.Unlock()
+	readyChecksByGVK[gvk] = wrapped
+	readyChecksByType[reflect.TypeOf(*new(T))] = wrapped
+}
+
+func lookupReadyCheck[T runtime.Object]() (readyCheck, bool) {
+	readyChecksMu.RLock()
+	defer readyChecksMu.RUnlock()
+	check, ok := readyChecksByType[reflect.TypeOf(*new(T))]
+	return check, ok
+}
+
+// ForReady polls objectFetcher until the fetched object's registered ready check passes (see
+// RegisterReadyCheck), feeding through ForObjectCondition so retry/timeout behavior matches every
+// other wait in this package.
+func ForReady[T runtime.Object](objectIdentifier string, objectFetcher func() (T, error), opts Opts) error {
+	check, ok := lookupReadyCheck[T]()
+	if !ok {
+		var zero T
+		return fmt.Errorf("no ready check registered for %T", zero)
+	}
+	return ForObjectCondition(objectIdentifier, objectFetcher, "ready", func(obj T) (bool, error) {
+		ready, reason, err := check(obj)
+		if err != nil {
+			return false, err
+		}
+		if !ready {
+			e2elog.Logf("%s not ready yet: %s", objectIdentifier, reason)
+		}
+		return ready, nil
+	}, opts)
+}
+
+// ForRollout is like ForReady for a Deployment, but additionally requires that no older
+// ReplicaSet still has replicas serving traffic; a Deployment can satisfy the plain readiness
+// check (the new ReplicaSet is fully available) while an old one is still being scaled down.
+// oldReplicaSetsFetcher should return the Deployment's non-current ReplicaSets, e.g. by listing
+// with its pod template hash label selector and excluding the one matching the current
+// rs-pod-template-hash.
+func ForRollout(
+	objectIdentifier string, objectFetcher func() (*appsv1.Deployment, error),
+	oldReplicaSetsFetcher func() ([]appsv1.ReplicaSet, error),
+	opts Opts) error {
+	check, ok := lookupReadyCheck[*appsv1.Deployment]()
+	if !ok {
+		return fmt.Errorf("no ready check registered for %T", &appsv1.Deployment{})
+	}
+	return ForObjectCondition(objectIdentifier, objectFetcher, "rolled out", func(obj *appsv1.Deployment) (bool, error) {
+		ready, reason, err := check(obj)
+		if err != nil {
+			return false, err
+		}
+		if !ready {
+			e2elog.Logf("%s not rolled out yet: %s", objectIdentifier, reason)
+			return false, nil
+		}
+
+		oldReplicaSets, err := oldReplicaSetsFetcher()
+		if err != nil {
+			return false, err
+		}
+		for _, rs := range oldReplicaSets {
+			if rs.Status.Replicas > 0 {
+				e2elog.Logf("%s not rolled out yet: old ReplicaSet %s still has %d replicas", objectIdentifier, rs.Name, rs.Status.Replicas)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, opts)
+}
+
+func init() {
+	RegisterReadyCheck(appsv1.SchemeGroupVersion.WithKind("Deployment"), func(d *appsv1.Deployment) (bool, string, error) {
+		if d.Status.ObservedGeneration < d.Generation {
+			return false, "observed generation is behind the latest spec", nil
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		if d.Status.UpdatedReplicas != replicas {
+			return false, fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, replicas), nil
+		}
+		if d.Status.AvailableReplicas != replicas {
+			return false, fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, replicas), nil
+		}
+		for _, cond := range d.Status.Conditions {
+			if cond.Type == appsv1.DeploymentProgressing && cond.Status == v1.ConditionFalse && cond.Reason == "ProgressDeadlineExceeded" {
+				return false, fmt.Sprintf("progress deadline exceeded: %s", cond.Message), nil
+			}
+		}
+		return true, "", nil
+	})
+
+	RegisterReadyCheck(appsv1.SchemeGroupVersion.WithKind("DaemonSet"), func(ds *appsv1.DaemonSet) (bool, string, error) {
+		if ds.Status.ObservedGeneration < ds.Generation {
+			return false, "observed generation is behind the latest spec", nil
+		}
+		if ds.Status.NumberReady != ds.Status.DesiredNumberScheduled {
+			return false, fmt.Sprintf("%d of %d desired pods ready", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled), nil
+		}
+		return true, "", nil
+	})
+
+	RegisterReadyCheck(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), func(ss *appsv1.StatefulSet) (bool, string, error) {
+		if ss.Status.ObservedGeneration < ss.Generation {
+			return false, "observed generation is behind the latest spec", nil
+		}
+		replicas := int32(1)
+		if ss.Spec.Replicas != nil {
+			replicas = *ss.Spec.Replicas
+		}
+		if ss.Status.ReadyReplicas != replicas {
+			return false, fmt.Sprintf("%d of %d replicas ready", ss.Status.ReadyReplicas, replicas), nil
+		}
+		if ss.Status.CurrentRevision != ss.Status.UpdateRevision {
+			return false, fmt.Sprintf("current revision %q has not yet caught up to update revision %q", ss.Status.CurrentRevision, ss.Status.UpdateRevision), nil
+		}
+		return true, "", nil
+	})
+
+	RegisterReadyCheck(batchv1.SchemeGroupVersion.WithKind("Job"), func(job *batchv1.Job) (bool, string, error) {
+		for _, cond := range job.Status.Conditions {
+			if cond.Type == batchv1.JobComplete && cond.Status == v1.ConditionTrue {
+				return true, "", nil
+			}
+		}
+		completions := int32(1)
+		if job.Spec.Completions != nil {
+			completions = *job.Spec.Completions
+		}
+		if job.Status.Succeeded >= completions {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%d of %d completions succeeded", job.Status.Succeeded, completions), nil
+	})
+
+	RegisterReadyCheck(v1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), func(pvc *v1.PersistentVolumeClaim) (bool, string, error) {
+		if pvc.Status.Phase != v1.ClaimBound {
+			return false, fmt.Sprintf("phase is %q, not Bound", pvc.Status.Phase), nil
+		}
+		return true, "", nil
+	})
+
+	RegisterReadyCheck(v1.SchemeGroupVersion.WithKind("Service"), func(svc *v1.Service) (bool, string, error) {
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			return true, "", nil
+		}
+		if len(svc.Status.LoadBalancer.Ingress) == 0 {
+			return false, "no load balancer ingress assigned yet", nil
+		}
+		return true, "", nil
+	})
+
+	RegisterReadyCheck(v1.SchemeGroupVersion.WithKind("Pod"), func(pod *v1.Pod) (bool, string, error) {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == v1.PodReady {
+				if cond.Status == v1.ConditionTrue {
+					return true, "", nil
+				}
+				return false, cond.Message, nil
+			}
+		}
+		return false, "PodReady condition not reported yet", nil
+	})
+}
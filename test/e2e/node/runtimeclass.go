@@ -22,10 +22,12 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/api/node/v1beta1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtimeclasstest "k8s.io/kubernetes/pkg/kubelet/runtimeclass/testing"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2ewait "k8s.io/kubernetes/test/e2e/framework/wait"
 	"k8s.io/kubernetes/test/e2e/scheduling"
 	imageutils "k8s.io/kubernetes/test/utils/image"
 	utilpointer "k8s.io/utils/pointer"
@@ -65,6 +67,10 @@ var _ = ginkgo.Describe("[sig-node] RuntimeClass", func() {
 	ginkgo.It("should run a Pod requesting a RuntimeClass with NodeSelector [NodeFeature:RuntimeHandler]", func() {
 		testRuntimeClassScheduling(f, false)
 	})
+
+	ginkgo.It("should account for the RuntimeClass Overhead when scheduling pods [NodeFeature:RuntimeHandler] [Disruptive]", func() {
+		testRuntimeClassOverhead(f)
+	})
 })
 
 func testRuntimeClassScheduling(f *framework.Framework, testTaints bool) {
@@ -135,6 +141,83 @@ func testRuntimeClassScheduling(f *framework.Framework, testTaints bool) {
 	framework.ExpectEqual(expectedTolerations, pod.Spec.Tolerations)
 }
 
+// testRuntimeClassOverhead verifies that a RuntimeClass's Overhead is applied to the Pod by
+// admission and that the scheduler accounts for it when fitting Pods onto a node. The kubelet's
+// eviction manager is not exercised.
+func testRuntimeClassOverhead(f *framework.Framework) {
+	nodeName := scheduling.GetNodeThatCanRunPod(f)
+	node, err := f.ClientSet.CoreV1().Nodes().Get(nodeName, metav1.GetOptions{})
+	framework.ExpectNoError(err, "failed to get node %q", nodeName)
+	allocatable, ok := node.Status.Allocatable[v1.ResourceMemory]
+	framework.ExpectEqual(ok, true, "node %q reports no allocatable memory", nodeName)
+
+	ginkgo.By("Trying to apply a label on the found node.")
+	const nodeLabelKey = "test-runtimeclass-overhead"
+	framework.AddOrUpdateLabelOnNode(f.ClientSet, nodeName, nodeLabelKey, f.Namespace.Name)
+	framework.ExpectNodeHasLabel(f.ClientSet, nodeName, nodeLabelKey, f.Namespace.Name)
+	defer framework.RemoveLabelOffNode(f.ClientSet, nodeName, nodeLabelKey)
+
+	// Overhead large enough that it pushes a Pod requesting a sizable fraction of the node's
+	// allocatable memory over the top, so under-accounting for it would let the scheduler place a
+	// second Pod that doesn't fit.
+	overhead := v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(allocatable.Value()/2, resource.BinarySI)}
+	request := v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(allocatable.Value()/2, resource.BinarySI)}
+
+	ginkgo.By("Creating a RuntimeClass with Overhead and a Pod requesting it")
+	runtimeClass := newRuntimeClass(f.Namespace.Name, "overhead-runtimeclass")
+	runtimeClass.Overhead = &v1beta1.Overhead{PodFixed: overhead}
+	rc, err := f.ClientSet.NodeV1beta1().RuntimeClasses().Create(runtimeClass)
+	framework.ExpectNoError(err, "failed to create RuntimeClass resource")
+
+	pod := newRuntimeClassPod(rc.GetName())
+	pod.Spec.NodeSelector = map[string]string{nodeLabelKey: f.Namespace.Name}
+	pod.Spec.Containers[0].Command = []string{"sleep", "3600"}
+	pod.Spec.Containers[0].Resources.Requests = request
+	pod.Spec.Containers[0].Resources.Limits = request
+	pod = f.PodClient().Create(pod)
+
+	ginkgo.By("Verifying the pod's Overhead is populated by admission and it is scheduled to the labeled node")
+	framework.ExpectNoError(e2ewait.ForObjectCondition(
+		fmt.Sprintf("pod %s/%s", f.Namespace.Name, pod.Name),
+		func() (*v1.Pod, error) {
+			return f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(pod.Name, metav1.GetOptions{})
+		},
+		"scheduled",
+		func(p *v1.Pod) (bool, error) { return p.Spec.NodeName != "", nil },
+		e2ewait.Opts{},
+	))
+	scheduledPod, err := f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(pod.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err)
+	framework.ExpectEqual(scheduledPod.Spec.Overhead, overhead)
+	framework.ExpectEqual(nodeName, scheduledPod.Spec.NodeName)
+
+	ginkgo.By("Verifying a second pod requesting the remaining allocatable memory is rejected once Overhead is accounted for")
+	secondPod := newRuntimeClassPod(rc.GetName())
+	secondPod.Spec.NodeSelector = map[string]string{nodeLabelKey: f.Namespace.Name}
+	secondPod.Spec.Containers[0].Command = []string{"sleep", "3600"}
+	remaining := *resource.NewQuantity(allocatable.Value()-request.Memory().Value(), resource.BinarySI)
+	secondPod.Spec.Containers[0].Resources.Requests = v1.ResourceList{v1.ResourceMemory: remaining}
+	secondPod.Spec.Containers[0].Resources.Limits = v1.ResourceList{v1.ResourceMemory: remaining}
+	secondPod = f.PodClient().Create(secondPod)
+
+	framework.ExpectNoError(e2ewait.ForObjectCondition(
+		fmt.Sprintf("pod %s/%s", f.Namespace.Name, secondPod.Name),
+		func() (*v1.Pod, error) {
+			return f.ClientSet.CoreV1().Pods(f.Namespace.Name).Get(secondPod.Name, metav1.GetOptions{})
+		},
+		"unschedulable due to insufficient memory once Overhead is counted",
+		func(p *v1.Pod) (bool, error) {
+			for _, cond := range p.Status.Conditions {
+				if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		e2ewait.Opts{},
+	))
+}
+
 // newRuntimeClass returns a test runtime class.
 func newRuntimeClass(namespace, name string) *v1beta1.RuntimeClass {
 	uniqueName := fmt.Sprintf("%s-%s", namespace, name)
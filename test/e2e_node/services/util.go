@@ -25,6 +25,8 @@ import (
 	"syscall"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -47,10 +49,116 @@ func waitForTerminationSignal() {
 	<-sig
 }
 
-// readinessCheck checks whether services are ready via the supplied health
-// check URLs. Once there is an error in errCh, the function will stop waiting
-// and return the error.
-func readinessCheck(name string, urls []string, errCh <-chan error) error {
+// ReadinessProbe checks whether some resource managed by an e2e service is ready. Unlike a health
+// check URL, a ReadinessProbe can look past a process accepting connections to whether the
+// Kubernetes-style resource(s) it manages have actually rolled out.
+//
+// A returned error indicates the check itself failed (e.g. a transient API error) rather than
+// that the resource is definitely unready; readinessCheck treats both the same way, as "not ready
+// yet", and keeps polling.
+type ReadinessProbe interface {
+	Ready() (bool, error)
+}
+
+// NewDeploymentReadinessProbe returns a ReadinessProbe that is ready once the Deployment returned
+// by getDeployment has rolled out: observedGeneration caught up, the full replica count is
+// updated/ready/available, and no condition reports Progressing=False.
+func NewDeploymentReadinessProbe(getDeployment func() (*appsv1.Deployment, error)) ReadinessProbe {
+	return readinessProbeFunc(func() (bool, error) {
+		d, err := getDeployment()
+		if err != nil {
+			return false, err
+		}
+		if d.Status.ObservedGeneration < d.Generation {
+			return false, nil
+		}
+		for _, cond := range d.Status.Conditions {
+			if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse {
+				return false, nil
+			}
+		}
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		return d.Status.UpdatedReplicas == replicas &&
+			d.Status.ReadyReplicas == replicas &&
+			d.Status.AvailableReplicas == replicas, nil
+	})
+}
+
+// NewStatefulSetReadinessProbe returns a ReadinessProbe that is ready once the StatefulSet
+// returned by getStatefulSet has rolled out, respecting a partitioned rolling update: only the
+// replicas at or above the partition ordinal need to be updated.
+func NewStatefulSetReadinessProbe(getStatefulSet func() (*appsv1.StatefulSet, error)) ReadinessProbe {
+	return readinessProbeFunc(func() (bool, error) {
+		s, err := getStatefulSet()
+		if err != nil {
+			return false, err
+		}
+		replicas := int32(1)
+		if s.Spec.Replicas != nil {
+			replicas = *s.Spec.Replicas
+		}
+		var partition int32
+		if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+			partition = *ru.Partition
+		}
+		return s.Status.UpdatedReplicas >= replicas-partition &&
+			s.Status.ReadyReplicas == replicas, nil
+	})
+}
+
+// NewDaemonSetReadinessProbe returns a ReadinessProbe that is ready once the DaemonSet returned by
+// getDaemonSet has observed its latest generation and has a ready pod on every node it is
+// scheduled to. Whether those pods run the latest template is not checked.
+func NewDaemonSetReadinessProbe(getDaemonSet func() (*appsv1.DaemonSet, error)) ReadinessProbe {
+	return readinessProbeFunc(func() (bool, error) {
+		d, err := getDaemonSet()
+		if err != nil {
+			return false, err
+		}
+		return d.Status.ObservedGeneration >= d.Generation &&
+			d.Status.NumberReady == d.Status.DesiredNumberScheduled, nil
+	})
+}
+
+// NewPodReadinessProbe returns a ReadinessProbe that is ready once the Pod returned by getPod has
+// a true PodReady condition and every container status reports Ready.
+func NewPodReadinessProbe(getPod func() (*corev1.Pod, error)) ReadinessProbe {
+	return readinessProbeFunc(func() (bool, error) {
+		p, err := getPod()
+		if err != nil {
+			return false, err
+		}
+		podReady := false
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				podReady = true
+				break
+			}
+		}
+		if !podReady {
+			return false, nil
+		}
+		for _, cs := range p.Status.ContainerStatuses {
+			if !cs.Ready {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// readinessProbeFunc adapts a plain func to a ReadinessProbe.
+type readinessProbeFunc func() (bool, error)
+
+func (f readinessProbeFunc) Ready() (bool, error) { return f() }
+
+// readinessCheck checks whether services are ready via the supplied health check URLs and
+// ReadinessProbes. Once there is an error in errCh, the function will stop waiting and return the
+// error.
+func readinessCheck(name string, urls []string, probes []ReadinessProbe, errCh <-chan error) error {
 	klog.Infof("Running readiness check for service %q", name)
 	endTime := time.Now().Add(*serverStartTimeout)
 	blockCh := make(chan error)
@@ -82,6 +190,21 @@ func readinessCheck(name string, urls []string, errCh <-chan error) error {
 					break
 				}
 			}
+			for _, probe := range probes {
+				if !ready {
+					break
+				}
+				probeReady, err := probe.Ready()
+				if err != nil {
+					klog.Infof("Readiness probe for service %q failed, will retry: %v", name, err)
+					ready = false
+					break
+				}
+				if !probeReady {
+					ready = false
+					break
+				}
+			}
 			if ready {
 				return nil
 			}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e_node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = framework.KubeDescribe("Summary API", func() {
+	f := framework.NewDefaultFramework("summary-metrics-test")
+	Context("when scraping /stats/summary/metrics", func() {
+		It("it should report resource usage through the Prometheus metrics endpoint", func() {
+			const pod0 = "stats-metrics-busybox-0"
+
+			By("Creating a test pod")
+			createSummaryTestPods(f, pod0)
+
+			By("Scraping the metrics endpoint")
+			Eventually(func() (string, error) {
+				resp, err := http.Get(*kubeletAddress + "/stats/summary/metrics")
+				if err != nil {
+					return "", fmt.Errorf("Failed to get /stats/summary/metrics - %v", err)
+				}
+				defer resp.Body.Close()
+				body, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					return "", fmt.Errorf("Failed to read /stats/summary/metrics - %v", err)
+				}
+				return string(body), nil
+			}, 30*time.Second, time.Second*15).Should(SatisfyAll(
+				matchPromGauge("node_cpu_usage_nano_cores", nil, 100E3, 2E9),
+				matchPromGauge("node_memory_working_set_bytes", nil, 10*mb, 100*gb),
+				matchPromGauge("node_fs_available_bytes", nil, 100*mb, 10*tb),
+			))
+		})
+	})
+})
+
+// matchPromGauge returns a Gomega matcher over a Prometheus text-exposition body: it parses the
+// body with expfmt and asserts that the first series of the named gauge carrying the given labels
+// (nil or empty matches a label-less series) has a value within [lower, upper]; later series that
+// also carry those labels are not consulted.
+func matchPromGauge(name string, labels map[string]string, lower, upper float64) types.GomegaMatcher {
+	return &promGaugeMatcher{name: name, labels: labels, lower: lower, upper: upper}
+}
+
+type promGaugeMatcher struct {
+	name         string
+	labels       map[string]string
+	lower, upper float64
+
+	failureMessage string
+}
+
+func (m *promGaugeMatcher) Match(actual interface{}) (bool, error) {
+	body, ok := actual.(string)
+	if !ok {
+		return false, fmt.Errorf("matchPromGauge expects a string, got %T", actual)
+	}
+
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(strings.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse Prometheus text format: %v", err)
+	}
+
+	family, ok := families[m.name]
+	if !ok {
+		m.failureMessage = fmt.Sprintf("metric family %q not found in scrape", m.name)
+		return false, nil
+	}
+
+	for _, metric := range family.GetMetric() {
+		if !labelsMatch(metric.GetLabel(), m.labels) {
+			continue
+		}
+		value := metric.GetGauge().GetValue()
+		if value < m.lower || value > m.upper {
+			m.failureMessage = fmt.Sprintf("%s = %v, expected within [%v, %v]", m.name, value, m.lower, m.upper)
+			return false, nil
+		}
+		return true, nil
+	}
+
+	m.failureMessage = fmt.Sprintf("no series for metric family %q matched labels %v", m.name, m.labels)
+	return false, nil
+}
+
+func (m *promGaugeMatcher) FailureMessage(actual interface{}) string {
+	return m.failureMessage
+}
+
+func (m *promGaugeMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("expected %s not to match, but it did", m.name)
+}
+
+// labelsMatch reports whether actual contains every name/value pair in expected. An empty or nil
+// expected matches a metric with no labels at all.
+func labelsMatch(actual []*dto.LabelPair, expected map[string]string) bool {
+	if len(expected) == 0 {
+		return len(actual) == 0
+	}
+	values := make(map[string]string, len(actual))
+	for _, pair := range actual {
+		values[pair.GetName()] = pair.GetValue()
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			return false
+		}
+	}
+	return true
+}
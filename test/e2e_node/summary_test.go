@@ -21,12 +21,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/resource"
-	"k8s.io/kubernetes/pkg/kubelet/api/v1alpha1/stats"
+	stats "k8s.io/kubernetes/pkg/apis/metrics"
 	"k8s.io/kubernetes/test/e2e/framework"
 	m "k8s.io/kubernetes/test/matchers"
 
@@ -35,16 +36,35 @@ import (
 	"github.com/onsi/gomega/types"
 )
 
+// acceleratorResourceEnv names a fake extended resource (e.g. "example.com/fake-gpu") that, if
+// set, this test requests for an extra pod so it can assert ContainerStats.Accelerators is
+// populated. Most runs don't set it: asserting real accelerator stats requires a node that
+// actually has a device plugin wired up to report them, which conformance infrastructure doesn't
+// provide.
+const acceleratorResourceEnv = "NODE_E2E_FAKE_ACCELERATOR_RESOURCE"
+
+// acceleratorDeviceIDEnv, if set alongside acceleratorResourceEnv, is the DeviceID the test
+// expects the fake accelerator's stats to be reported under. Defaults to "fake-gpu-0".
+const acceleratorDeviceIDEnv = "NODE_E2E_FAKE_ACCELERATOR_DEVICE_ID"
+
 var _ = framework.KubeDescribe("Summary API", func() {
 	f := framework.NewDefaultFramework("summary-test")
 	Context("when querying /stats/summary", func() {
 		It("it should report resource usage through the stats api", func() {
 			const pod0 = "stats-busybox-0"
 			const pod1 = "stats-busybox-1"
+			const acceleratorPodName = "stats-accelerator-0"
+			const acceleratorContainerName = "accelerator-container"
 
 			By("Creating test pods")
 			createSummaryTestPods(f, pod0, pod1)
 
+			acceleratorResource := os.Getenv(acceleratorResourceEnv)
+			if acceleratorResource != "" {
+				By("Creating an accelerator test pod")
+				createAcceleratorTestPod(f, acceleratorPodName, acceleratorContainerName, acceleratorResource)
+			}
+
 			// // Setup expectations
 			// lower := lowerBound
 			// lower.Pods = []stats.PodStats{
@@ -57,9 +77,24 @@ var _ = framework.KubeDescribe("Summary API", func() {
 			// 	namedPod(f.Namespace.Name, pod1, podUpper),
 			// }
 
+			// Load the bounds this run checks against. summaryProfileNames picks the overlays
+			// for this node's size/cgroup version/container runtime, so the same test asserts
+			// tight bounds where the infrastructure is known and loose ones elsewhere, instead
+			// of every bound having to fit every node conformance ever runs on.
+			profile, err := loadSummaryProfile(summaryProfileNames()...)
+			Expect(err).NotTo(HaveOccurred())
+
 			// Setup expectations.
-			fsCapacityBounds := bounded(100*mb, 10*tb)
-			match := m.StrictStruct(m.Fields{
+			interfaceStatsMatcher := m.StrictStruct(m.Fields{
+				"Name":      m.Ignore(),
+				"RxBytes":   boundFrom(profile.Network.RxBytes),
+				"RxErrors":  boundFrom(profile.Network.RxErrors),
+				"RxDropped": boundFrom(profile.Network.RxDropped),
+				"TxBytes":   boundFrom(profile.Network.TxBytes),
+				"TxErrors":  boundFrom(profile.Network.TxErrors),
+				"TxDropped": boundFrom(profile.Network.TxDropped),
+			})
+			match := withReport(m.StrictStruct(m.Fields{
 				"Node": m.StrictStruct(m.Fields{
 					"NodeName":  m.Ignore(),
 					"StartTime": m.Recent(time.Hour * 24 * 365), // 1 year
@@ -67,108 +102,51 @@ var _ = framework.KubeDescribe("Summary API", func() {
 						"kubelet": m.StrictStruct(m.Fields{
 							"Name":      m.Ignore(),
 							"StartTime": m.Recent(time.Hour * 24 * 365), // 1 year
-							"CPU": structP(m.Fields{
-								"Time":                 m.Recent(time.Minute),
-								"UsageNanoCores":       bounded(100000, 2E9),
-								"UsageCoreNanoSeconds": bounded(10000000, 1E15),
-							}),
-							"Memory": structP(m.Fields{
-								"Time":            m.Recent(time.Minute),
-								"AvailableBytes":  bounded(100*mb, 100*gb),
-								"UsageBytes":      bounded(10*mb, 10*gb),
-								"WorkingSetBytes": bounded(10*mb, 1*gb),
-								"RSSBytes":        bounded(10*mb, 1*gb),
-								"PageFaults":      bounded(1000, 1E9),
-								"MajorPageFaults": bounded(0, 100000),
-							}),
-							"Rootfs": structP(m.Fields{
-								"AvailableBytes": fsCapacityBounds,
-								"CapacityBytes":  fsCapacityBounds,
-								"UsedBytes":      bounded(0, 0), // Kubelet doesn't write.
-								"InodesFree":     bounded(1E4, 1E6),
-							}),
-							"Logs": structP(m.Fields{
-								"AvailableBytes": fsCapacityBounds,
-								"CapacityBytes":  fsCapacityBounds,
-								"UsedBytes":      bounded(kb, 10*gb),
-								"InodesFree":     bounded(1E4, 1E6),
-							}),
+							"CPU":       cpuMatcher(profile.Kubelet),
+							"Memory":    memoryMatcher(profile.Kubelet),
+							"Rootfs":    fsMatcher(profile.Fs, profile.Kubelet.RootfsUsedBytes),
+							"Logs":      fsMatcher(profile.Fs, profile.Kubelet.LogsUsedBytes),
 						}),
 						"runtime": m.StrictStruct(m.Fields{
 							"Name":      m.Ignore(),
 							"StartTime": m.Recent(time.Hour * 24 * 365), // 1 year
-							"CPU": structP(m.Fields{
-								"Time":                 m.Recent(time.Minute),
-								"UsageNanoCores":       bounded(100000, 2E9),
-								"UsageCoreNanoSeconds": bounded(10000000, 1E15),
-							}),
-							"Memory": structP(m.Fields{
-								"Time":            m.Recent(time.Minute),
-								"AvailableBytes":  bounded(100*mb, 100*gb),
-								"UsageBytes":      bounded(100*mb, 10*gb),
-								"WorkingSetBytes": bounded(10*mb, 1*gb),
-								"RSSBytes":        bounded(10*mb, 1*gb),
-								"PageFaults":      bounded(100000, 1E9),
-								"MajorPageFaults": bounded(0, 100000),
-							}),
-							"Rootfs": structP(m.Fields{
-								"AvailableBytes": fsCapacityBounds,
-								"CapacityBytes":  fsCapacityBounds,
-								"UsedBytes":      bounded(0, 10*gb),
-								"InodesFree":     bounded(1E4, 1E6),
-							}),
-							"Logs": structP(m.Fields{
-								"AvailableBytes": fsCapacityBounds,
-								"CapacityBytes":  fsCapacityBounds,
-								"UsedBytes":      bounded(kb, 10*gb),
-								"InodesFree":     bounded(1E4, 1E6),
-							}),
+							"CPU":       cpuMatcher(profile.Runtime),
+							"Memory":    memoryMatcher(profile.Runtime),
+							"Rootfs":    fsMatcher(profile.Fs, profile.Runtime.RootfsUsedBytes),
+							"Logs":      fsMatcher(profile.Fs, profile.Runtime.LogsUsedBytes),
 						}),
 					}),
-					"CPU": structP(m.Fields{
-						"Time":                 m.Recent(time.Minute),
-						"UsageNanoCores":       bounded(100E3, 2E9),
-						"UsageCoreNanoSeconds": bounded(1E9, 1E15),
-					}),
-					"Memory": structP(m.Fields{
-						"Time":            m.Recent(time.Minute),
-						"AvailableBytes":  bounded(100*mb, 100*gb),
-						"UsageBytes":      bounded(10*mb, 100*gb),
-						"WorkingSetBytes": bounded(10*mb, 100*gb),
-						"RSSBytes":        bounded(1*mb, 100*gb),
-						"PageFaults":      bounded(1000, 1E9),
-						"MajorPageFaults": bounded(0, 100000),
-					}),
-					// TODO: Handle non-eth0 network interface names.
+					"CPU":    cpuMatcher(profile.Node),
+					"Memory": memoryMatcher(profile.Node),
 					"Network": m.NilOr(
 						structP(m.Fields{
-							"Time":     m.Recent(time.Minute),
-							"RxBytes":  bounded(1*mb, 100*gb),
-							"RxErrors": bounded(0, 100000),
-							"TxBytes":  bounded(10*kb, 10*gb),
-							"TxErrors": bounded(0, 100000),
+							"Time":           m.Recent(time.Minute),
+							"InterfaceStats": interfaceStatsMatcher,
+							// The default interface is assumed to be named "eth0", but extra
+							// interfaces (bridges, VLANs, SR-IOV VFs, ...) are tolerated so this
+							// matches multi-NIC hosts as well as the common single-NIC case.
+							"Interfaces": m.LooseSlice(interfaceStatsID, m.IgnoreExtras|m.IgnoreMissing, m.Elements{
+								"eth0": interfaceStatsMatcher,
+							}),
 						}),
 					),
-					"Fs": structP(m.Fields{
-						"AvailableBytes": fsCapacityBounds,
-						"CapacityBytes":  fsCapacityBounds,
-						"UsedBytes":      bounded(kb, 10*gb),
-						"InodesFree":     bounded(1E4, 1E6),
-					}),
+					"Fs": fsMatcher(profile.Fs, profile.Fs.UsedBytes),
 					"Runtime": structP(m.Fields{
-						"ImageFs": structP(m.Fields{
-							"AvailableBytes": fsCapacityBounds,
-							"CapacityBytes":  fsCapacityBounds,
-							"UsedBytes":      bounded(kb, 10*gb),
-							"InodesFree":     bounded(1E4, 1E6),
-						}),
+						"ImageFs": fsMatcher(profile.ImageFs, profile.ImageFs.UsedBytes),
 					}),
+					"PSI": m.NilOr(
+						structP(m.Fields{
+							"Time":   m.Recent(time.Minute),
+							"CPU":    m.NilOr(psiDataMatcher()),
+							"Memory": m.NilOr(psiDataMatcher()),
+							"IO":     m.NilOr(psiDataMatcher()),
+						}),
+					),
 				}),
 				"Pods": m.Ignore(),
-			})
+			}))
 
-			By("Returning stats summary")
-			Eventually(func() (stats.Summary, error) {
+			fetchSummary := func() (stats.Summary, error) {
 				summary := stats.Summary{}
 				resp, err := http.Get(*kubeletAddress + "/stats/summary")
 				if err != nil {
@@ -186,7 +164,45 @@ var _ = framework.KubeDescribe("Summary API", func() {
 				}
 
 				return summary, nil
-			}, /*1*time.Minute FIXME */ 30*time.Second, time.Second*15).Should(match)
+			}
+
+			By("Returning stats summary")
+			Eventually(fetchSummary /*1*time.Minute FIXME */, 30*time.Second, time.Second*15).Should(match)
+
+			if acceleratorResource != "" {
+				deviceID := os.Getenv(acceleratorDeviceIDEnv)
+				if deviceID == "" {
+					deviceID = "fake-gpu-0"
+				}
+
+				By("Returning accelerator stats for the accelerator test pod")
+				Eventually(func() ([]stats.AcceleratorStats, error) {
+					summary, err := fetchSummary()
+					if err != nil {
+						return nil, err
+					}
+					for _, pod := range summary.Pods {
+						if pod.PodRef.Name != acceleratorPodName {
+							continue
+						}
+						for _, container := range pod.Containers {
+							if container.Name == acceleratorContainerName {
+								return container.Accelerators, nil
+							}
+						}
+					}
+					return nil, fmt.Errorf("accelerator test pod %s/%s not found in summary", f.Namespace.Name, acceleratorPodName)
+				}, 30*time.Second, time.Second*15).Should(m.StrictSlice(acceleratorStatsID, m.Elements{
+					deviceID: m.StrictStruct(m.Fields{
+						"DeviceID":    m.Ignore(),
+						"Make":        m.Ignore(),
+						"Model":       m.Ignore(),
+						"MemoryTotal": bounded(1*mb, 100*gb),
+						"MemoryUsed":  bounded(0, 100*gb),
+						"DutyCycle":   bounded(0, 100),
+					}),
+				}))
+			}
 		})
 	})
 })
@@ -233,6 +249,33 @@ func createSummaryTestPods(f *framework.Framework, names ...string) {
 	f.PodClient().CreateBatch(pods)
 }
 
+// createAcceleratorTestPod creates a pod requesting one unit of the given extended resource, so
+// this test can assert its device plugin's stats show up in ContainerStats.Accelerators.
+func createAcceleratorTestPod(f *framework.Framework, name, containerName, resourceName string) {
+	f.PodClient().CreateBatch([]*api.Pod{
+		{
+			ObjectMeta: api.ObjectMeta{
+				Name: name,
+			},
+			Spec: api.PodSpec{
+				RestartPolicy: api.RestartPolicyNever,
+				Containers: []api.Container{
+					{
+						Name:    containerName,
+						Image:   ImageRegistry[busyBoxImage],
+						Command: []string{"sh", "-c", "while true; do sleep 1; done"},
+						Resources: api.ResourceRequirements{
+							Limits: api.ResourceList{
+								api.ResourceName(resourceName): resource.MustParse("1"),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
 const (
 	kb = 1000
 	mb = 1000 * kb
@@ -548,6 +591,14 @@ func summaryObjectID(element interface{}) string {
 	}
 }
 
+func interfaceStatsID(element interface{}) string {
+	return element.(stats.InterfaceStats).Name
+}
+
+func acceleratorStatsID(element interface{}) string {
+	return element.(stats.AcceleratorStats).DeviceID
+}
+
 func namedPod(namespace, name string, pod stats.PodStats) stats.PodStats {
 	pod.PodRef.Name = name
 	pod.PodRef.Namespace = namespace
@@ -562,3 +613,79 @@ func structP(fields m.Fields) types.GomegaMatcher {
 func bounded(lower, upper interface{}) types.GomegaMatcher {
 	return m.Ptr(m.InRange(lower, upper))
 }
+
+// boundFrom is bounded for a bound loaded from a summaryProfile.
+func boundFrom(b bound) types.GomegaMatcher {
+	return bounded(b.Lower, b.Upper)
+}
+
+// cpuMatcher matches a *stats.CPUStats against the bounds in b.
+func cpuMatcher(b containerBounds) types.GomegaMatcher {
+	return structP(m.Fields{
+		"Time":                 m.Recent(time.Minute),
+		"UsageNanoCores":       boundFrom(b.CPUUsageNanoCores),
+		"UsageCoreNanoSeconds": boundFrom(b.CPUUsageCoreNanoSeconds),
+	})
+}
+
+// memoryMatcher matches a *stats.MemoryStats against the bounds in b.
+func memoryMatcher(b containerBounds) types.GomegaMatcher {
+	return structP(m.Fields{
+		"Time":            m.Recent(time.Minute),
+		"AvailableBytes":  boundFrom(b.MemoryAvailableBytes),
+		"UsageBytes":      boundFrom(b.MemoryUsageBytes),
+		"WorkingSetBytes": boundFrom(b.MemoryWorkingSetBytes),
+		"RSSBytes":        boundFrom(b.MemoryRSSBytes),
+		"PageFaults":      boundFrom(b.MemoryPageFaults),
+		"MajorPageFaults": boundFrom(b.MemoryMajorPageFaults),
+	})
+}
+
+// fsMatcher matches a *stats.FsStats: AvailableBytes/CapacityBytes/InodesFree come from capacity
+// (the same filesystem-capacity bounds apply node-wide), while used is specific to the
+// filesystem being matched (e.g. the kubelet's rootfs is expected to stay empty, but its logs
+// directory isn't).
+func fsMatcher(capacity fsBounds, used bound) types.GomegaMatcher {
+	return structP(m.Fields{
+		"AvailableBytes": boundFrom(capacity.AvailableBytes),
+		"CapacityBytes":  boundFrom(capacity.CapacityBytes),
+		"UsedBytes":      boundFrom(used),
+		"InodesFree":     boundFrom(capacity.InodesFree),
+	})
+}
+
+// withReport wraps a StructMatcher so that a failed Match, in addition to the usual free-text
+// FailureMessage, logs the same mismatches as a JSON array of matchers.Mismatch records — one
+// per metric that drifted outside its profile bounds — so CI can diff a run's summary failures
+// mechanically instead of parsing a Gomega dump.
+func withReport(match *m.StructMatcher) types.GomegaMatcher {
+	return &reportingMatcher{StructMatcher: match}
+}
+
+type reportingMatcher struct {
+	*m.StructMatcher
+}
+
+func (r *reportingMatcher) Match(actual interface{}) (bool, error) {
+	ok, err := r.StructMatcher.Match(actual)
+	if !ok && err == nil {
+		if report, reportErr := m.Report(r.Failure()); reportErr == nil {
+			framework.Logf("Summary API mismatch report:\n%s", report)
+		}
+	}
+	return ok, err
+}
+
+// psiDataMatcher matches a *stats.PSIData: the Some/Full pressure averages for a single resource.
+func psiDataMatcher() types.GomegaMatcher {
+	psiLine := m.StrictStruct(m.Fields{
+		"Avg10":  m.InRange(0, 100),
+		"Avg60":  m.InRange(0, 100),
+		"Avg300": m.InRange(0, 100),
+		"Total":  bounded(0, 1E15),
+	})
+	return structP(m.Fields{
+		"Some": psiLine,
+		"Full": psiLine,
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/kubernetes/pkg/util/errors"
+
+	"github.com/onsi/gomega/format"
+)
+
+// MapMatcher matches a map key-by-key, keyed by fmt.Sprint of the map key. Like StructMatcher, a
+// value's matcher may itself be a *StructMatcher, *SliceMatcher, or *MapMatcher, and is matched
+// recursively with its errors nested under "[key]".
+type MapMatcher struct {
+	// Matchers for each key's value.
+	Values Elements
+	// Whether missing or extra keys are considered an error.
+	Strict bool
+	// IgnoreExtras allows keys present in the actual map but not listed in Values, even when
+	// Strict is set.
+	IgnoreExtras bool
+	// IgnoreMissing allows keys listed in Values that aren't actually present, even when Strict
+	// is set.
+	IgnoreMissing bool
+
+	failure error // the mismatches from the most recent failed Match, used by FailureMessage.
+}
+
+func (m *MapMatcher) Match(actual interface{}) (success bool, err error) {
+	if reflect.TypeOf(actual).Kind() != reflect.Map {
+		return false, fmt.Errorf("%v is type %T, expected map", actual, actual)
+	}
+
+	errs := m.matchValues(actual)
+	if len(errs) == 0 {
+		m.failure = nil
+		return true, nil
+	}
+	m.failure = errors.NewAggregate(errs)
+	return false, nil
+}
+
+func (m *MapMatcher) matchValues(actual interface{}) (errs []error) {
+	// Provide more useful error messages in the case of a panic.
+	defer func() {
+		if err := recover(); err != nil {
+			errs = append(errs, fmt.Errorf("panic checking %v: %v", actual, err))
+		}
+	}()
+
+	val := reflect.ValueOf(actual)
+	keys := map[string]bool{}
+	for _, keyVal := range val.MapKeys() {
+		key := fmt.Sprint(keyVal.Interface())
+		keys[key] = true
+		value := val.MapIndex(keyVal).Interface()
+
+		matcher, expected := m.Values[key]
+		if !expected {
+			if m.Strict && !m.IgnoreExtras {
+				errs = append(errs, fmt.Errorf("unexpected key %s: %s", key, format.Object(value, 1)))
+			}
+			continue
+		}
+
+		match, err := matcher.Match(value)
+		if err != nil {
+			errs = append(errs, Nest(fmt.Sprintf("[%s]", key), err))
+			continue
+		}
+		if !match {
+			errs = append(errs, Nest(fmt.Sprintf("[%s]", key), mismatchError(matcher, value)))
+		}
+	}
+
+	if m.Strict && !m.IgnoreMissing {
+		for key := range m.Values {
+			if !keys[key] {
+				errs = append(errs, fmt.Errorf("missing expected key %s", key))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (m *MapMatcher) FailureMessage(actual interface{}) (message string) {
+	if m.failure == nil {
+		return format.Message(actual, "to match map matcher")
+	}
+	return fmt.Sprintf("Expected%s\nto match map matcher, but found:\n%s",
+		format.Object(actual, 1), Diff(m.failure))
+}
+
+func (m *MapMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return format.Message(actual, "not to match map matcher")
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+func TestMapMatcher(t *testing.T) {
+	allKeys := map[string]string{"a": "a", "b": "b"}
+	missingKeys := map[string]string{"a": "a"}
+	extraKeys := map[string]string{"a": "a", "b": "b", "c": "c"}
+	empty := map[string]string{}
+
+	strict := StrictMap(Elements{
+		"b": gomega.Equal("b"),
+		"a": gomega.Equal("a"),
+	})
+	strictFail := StrictMap(Elements{
+		"a": gomega.Equal("a"),
+		"b": gomega.Equal("fail"),
+	})
+	strictEmpty := StrictMap(Elements{})
+	ignoreExtras := LooseMap(IgnoreExtras, Elements{
+		"b": gomega.Equal("b"),
+		"a": gomega.Equal("a"),
+	})
+	ignoreMissing := LooseMap(IgnoreMissing, Elements{
+		"a": gomega.Equal("a"),
+		"b": gomega.Equal("b"),
+	})
+	looseFail := LooseMap(IgnoreExtras|IgnoreMissing, Elements{
+		"a": gomega.Equal("a"),
+		"b": gomega.Equal("fail"),
+	})
+
+	tests := []struct {
+		actual      interface{}
+		matcher     types.GomegaMatcher
+		expectMatch bool
+		msg         string
+	}{
+		{allKeys, strict, true, "StrictMap should match all keys"},
+		{missingKeys, strict, false, "StrictMap should fail with missing keys"},
+		{extraKeys, strict, false, "StrictMap should fail with extra keys"},
+		{allKeys, strictFail, false, "StrictMap should fail with fail"},
+		{empty, strictEmpty, true, "StrictMap should handle empty maps"},
+		{allKeys, ignoreExtras, true, "LooseMap 'ignoreExtras' should match all keys"},
+		{missingKeys, ignoreExtras, false, "LooseMap 'ignoreExtras' should fail with missing keys"},
+		{extraKeys, ignoreExtras, true, "LooseMap 'ignoreExtras' should ignore extra keys"},
+		{allKeys, ignoreMissing, true, "LooseMap 'ignoreMissing' should match all keys"},
+		{missingKeys, ignoreMissing, true, "LooseMap 'ignoreMissing' should ignore missing keys"},
+		{extraKeys, ignoreMissing, false, "LooseMap 'ignoreMissing' should fail with extra keys"},
+		{allKeys, looseFail, false, "LooseMap should fail with fail"},
+	}
+
+	for i, test := range tests {
+		match, err := test.matcher.Match(test.actual)
+		assert.NoError(t, err, "[%d] %s", i, test.msg)
+		assert.Equal(t, test.expectMatch, match,
+			"[%d] %s: %s", i, test.msg, test.matcher.FailureMessage(test.actual))
+	}
+}
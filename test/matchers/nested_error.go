@@ -17,9 +17,13 @@ limitations under the License.
 package matchers
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"k8s.io/kubernetes/pkg/util/errors"
+
+	"github.com/onsi/gomega/types"
 )
 
 // An error type for labeling errors on deeply nested matchers.
@@ -54,3 +58,98 @@ func Nest(path string, err error) error {
 		Err:  err,
 	}
 }
+
+// Diff renders err as a newline-separated list of mismatches, one per offending field/element/
+// key, flattening any nested Aggregate so that a single failed Match produces one line per
+// mismatch rather than one opaque summary.
+func Diff(err error) string {
+	if ag, ok := err.(errors.Aggregate); ok {
+		lines := make([]string, 0, len(ag.Errors()))
+		for _, e := range ag.Errors() {
+			lines = append(lines, Diff(e))
+		}
+		return strings.Join(lines, "\n")
+	}
+	return err.Error()
+}
+
+// Mismatch is a single leaf-level failure produced by a StructMatcher/SliceMatcher/MapMatcher:
+// one field/element/key, at Path, that didn't match. LowerBound and UpperBound are populated
+// only when the failing matcher implements Bounded (e.g. RangeMatcher); Reason is always the
+// matcher's human-readable FailureMessage.
+type Mismatch struct {
+	Path       string      `json:"path"`
+	Actual     interface{} `json:"actual,omitempty"`
+	LowerBound interface{} `json:"lowerBound,omitempty"`
+	UpperBound interface{} `json:"upperBound,omitempty"`
+	Reason     string      `json:"reason"`
+}
+
+func (m *Mismatch) Error() string {
+	return m.Reason
+}
+
+// Bounded is implemented by matchers (e.g. RangeMatcher) that can describe their most recently
+// failed Match numerically. mismatchError uses it to attach the offending value and the bounds
+// it violated to the error it builds, instead of keeping only the free-text FailureMessage.
+type Bounded interface {
+	Bounds() (actual, lower, upper float64)
+}
+
+// mismatchError builds the error a StructMatcher/SliceMatcher/MapMatcher nests under the failing
+// field/element/key's path. If matcher is itself a *StructMatcher, *SliceMatcher or *MapMatcher,
+// its own mismatches are returned, so the caller nests them under its path. If matcher implements
+// Bounded, the result is a *Mismatch carrying the numeric bounds it violated, so Report can render
+// it as a structured record; otherwise only matcher's FailureMessage text survives.
+func mismatchError(matcher types.GomegaMatcher, value interface{}) error {
+	switch nested := matcher.(type) {
+	case *StructMatcher:
+		if nested.failure != nil {
+			return nested.failure
+		}
+	case *SliceMatcher:
+		if nested.failure != nil {
+			return nested.failure
+		}
+	case *MapMatcher:
+		if nested.failure != nil {
+			return nested.failure
+		}
+	}
+	reason := matcher.FailureMessage(value)
+	if b, ok := matcher.(Bounded); ok {
+		actual, lower, upper := b.Bounds()
+		return &Mismatch{Actual: actual, LowerBound: lower, UpperBound: upper, Reason: reason}
+	}
+	return fmt.Errorf("%s", reason)
+}
+
+// Report renders err (as produced by a failed StructMatcher/SliceMatcher/MapMatcher Match) as a
+// flat JSON array of Mismatch records, one per offending field/element/key, so CI can diff which
+// specific metric drifted instead of parsing a free-text Gomega dump.
+func Report(err error) ([]byte, error) {
+	return json.MarshalIndent(mismatches(err), "", "  ")
+}
+
+func mismatches(err error) []Mismatch {
+	if ag, ok := err.(errors.Aggregate); ok {
+		var out []Mismatch
+		for _, e := range ag.Errors() {
+			out = append(out, mismatches(e)...)
+		}
+		return out
+	}
+	if ne, ok := err.(*NestedError); ok {
+		m := asMismatch(ne.Err)
+		m.Path = ne.Path
+		return []Mismatch{m}
+	}
+	return []Mismatch{asMismatch(err)}
+}
+
+func asMismatch(err error) Mismatch {
+	if m, ok := err.(*Mismatch); ok {
+		return *m
+	}
+	return Mismatch{Reason: err.Error()}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import "github.com/onsi/gomega/types"
+
+// StrictnessOption relaxes one aspect of a Loose* matcher's strictness. Combine multiple options
+// with |, e.g. LooseStruct(IgnoreExtras|IgnoreMissing, fields).
+type StrictnessOption int
+
+const (
+	// IgnoreExtras allows fields/elements/keys present on the actual value that aren't listed in
+	// the matcher.
+	IgnoreExtras StrictnessOption = 1 << iota
+	// IgnoreMissing allows fields/elements/keys listed in the matcher that aren't present on the
+	// actual value.
+	IgnoreMissing
+)
+
+// StrictStruct returns a StructMatcher that requires every field of the actual struct to be
+// listed in fields, and every field listed in fields to be present.
+func StrictStruct(fields Fields) *StructMatcher {
+	return &StructMatcher{Fields: fields, Strict: true}
+}
+
+// LooseStruct returns a StructMatcher like StrictStruct, but tolerating the extra or missing
+// fields indicated by opts.
+func LooseStruct(opts StrictnessOption, fields Fields) *StructMatcher {
+	return &StructMatcher{
+		Fields:        fields,
+		Strict:        true,
+		IgnoreExtras:  opts&IgnoreExtras != 0,
+		IgnoreMissing: opts&IgnoreMissing != 0,
+	}
+}
+
+// StrictSlice returns a SliceMatcher that requires every element of the actual slice (identified
+// by identifier) to be listed in elements, and every listed element to be present.
+func StrictSlice(identifier Identifier, elements Elements) *SliceMatcher {
+	return &SliceMatcher{Identifier: identifier, Elements: elements, Strict: true}
+}
+
+// LooseSlice returns a SliceMatcher like StrictSlice, but tolerating the extra or missing
+// elements indicated by opts.
+func LooseSlice(identifier Identifier, opts StrictnessOption, elements Elements) *SliceMatcher {
+	return &SliceMatcher{
+		Identifier:    identifier,
+		Elements:      elements,
+		Strict:        true,
+		IgnoreExtras:  opts&IgnoreExtras != 0,
+		IgnoreMissing: opts&IgnoreMissing != 0,
+	}
+}
+
+// StrictMap returns a MapMatcher that requires every key of the actual map to be listed in
+// values, and every listed key to be present.
+func StrictMap(values Elements) *MapMatcher {
+	return &MapMatcher{Values: values, Strict: true}
+}
+
+// LooseMap returns a MapMatcher like StrictMap, but tolerating the extra or missing keys
+// indicated by opts.
+func LooseMap(opts StrictnessOption, values Elements) *MapMatcher {
+	return &MapMatcher{
+		Values:        values,
+		Strict:        true,
+		IgnoreExtras:  opts&IgnoreExtras != 0,
+		IgnoreMissing: opts&IgnoreMissing != 0,
+	}
+}
+
+// ignoreMatcher always succeeds, regardless of the actual value. Use it to document that a
+// field/element/key's value is intentionally not checked.
+type ignoreMatcher struct{}
+
+func (ignoreMatcher) Match(actual interface{}) (bool, error)          { return true, nil }
+func (ignoreMatcher) FailureMessage(actual interface{}) string        { return "" }
+func (ignoreMatcher) NegatedFailureMessage(actual interface{}) string { return "" }
+
+// Ignore returns a matcher that always succeeds.
+func Ignore() types.GomegaMatcher {
+	return ignoreMatcher{}
+}
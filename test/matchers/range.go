@@ -0,0 +1,84 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RangeMatcher matches a numeric value against an inclusive [Lower, Upper] bound. Unlike a plain
+// numeric comparison, a failed Match retains the actual value alongside both bounds (see Bounds),
+// so a StructMatcher/SliceMatcher/MapMatcher enclosing it can report the mismatch as a structured
+// record instead of free text.
+type RangeMatcher struct {
+	Lower, Upper float64
+
+	actual float64 // the value from the most recent Match call, for FailureMessage/Bounds.
+}
+
+// InRange returns a matcher that succeeds when the actual value, converted to float64, falls
+// within [lower, upper] inclusive. lower and upper may be any numeric type.
+func InRange(lower, upper interface{}) *RangeMatcher {
+	return &RangeMatcher{Lower: mustFloat64(lower), Upper: mustFloat64(upper)}
+}
+
+func (m *RangeMatcher) Match(actual interface{}) (success bool, err error) {
+	val, err := toFloat64(actual)
+	if err != nil {
+		return false, err
+	}
+	m.actual = val
+	return val >= m.Lower && val <= m.Upper, nil
+}
+
+func (m *RangeMatcher) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected %v\nto be within range [%v, %v]", actual, m.Lower, m.Upper)
+}
+
+func (m *RangeMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected %v\nnot to be within range [%v, %v]", actual, m.Lower, m.Upper)
+}
+
+// Bounds implements Bounded, letting mismatchError attach the numeric bounds the value violated
+// instead of only FailureMessage's text.
+func (m *RangeMatcher) Bounds() (actual, lower, upper float64) {
+	return m.actual, m.Lower, m.Upper
+}
+
+// toFloat64 converts a numeric value of any kind (int*, uint*, float*) to float64.
+func toFloat64(value interface{}) (float64, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	default:
+		return 0, fmt.Errorf("InRange: %v is type %T, expected a numeric type", value, value)
+	}
+}
+
+func mustFloat64(value interface{}) float64 {
+	f, err := toFloat64(value)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeMatcher(t *testing.T) {
+	tests := []struct {
+		actual      interface{}
+		lower       interface{}
+		upper       interface{}
+		expectMatch bool
+		msg         string
+	}{
+		{50, 0, 100, true, "int within range should match"},
+		{uint64(50), 0, 100, true, "uint64 within range should match"},
+		{50.5, 0, 100, true, "float within range should match"},
+		{0, 0, 100, true, "lower bound is inclusive"},
+		{100, 0, 100, true, "upper bound is inclusive"},
+		{-1, 0, 100, false, "value below range should fail"},
+		{101, 0, 100, false, "value above range should fail"},
+	}
+
+	for i, test := range tests {
+		m := InRange(test.lower, test.upper)
+		match, err := m.Match(test.actual)
+		assert.NoError(t, err, "[%d] %s", i, test.msg)
+		assert.Equal(t, test.expectMatch, match, "[%d] %s", i, test.msg)
+	}
+}
+
+func TestRangeMatcherBounds(t *testing.T) {
+	m := InRange(10, 20)
+
+	match, err := m.Match(5)
+	assert.NoError(t, err)
+	assert.False(t, match, "5 is not within [10, 20]")
+
+	actual, lower, upper := m.Bounds()
+	assert.Equal(t, 5.0, actual)
+	assert.Equal(t, 10.0, lower)
+	assert.Equal(t, 20.0, upper)
+}
+
+func TestRangeMatcherRejectsNonNumeric(t *testing.T) {
+	m := InRange(0, 100)
+	_, err := m.Match("not a number")
+	assert.Error(t, err, "InRange should error on a non-numeric actual value")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportStructured(t *testing.T) {
+	type Inner struct{ Value int }
+	type outer struct {
+		Name  string
+		Inner Inner
+	}
+
+	m := StrictStruct(Fields{
+		"Name":  Ignore(),
+		"Inner": StrictStruct(Fields{"Value": InRange(0, 10)}),
+	})
+
+	match, err := m.Match(outer{Name: "x", Inner: Inner{Value: 42}})
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	raw, err := Report(m.Failure())
+	assert.NoError(t, err)
+
+	var got []Mismatch
+	assert.NoError(t, json.Unmarshal(raw, &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, ".Inner.Value", got[0].Path)
+	assert.Equal(t, 42.0, got[0].Actual)
+	assert.Equal(t, 0.0, got[0].LowerBound)
+	assert.Equal(t, 10.0, got[0].UpperBound)
+}
+
+func TestReportUnstructured(t *testing.T) {
+	m := StrictStruct(Fields{"Name": equalMatcher{"expected"}})
+
+	type outer struct{ Name string }
+	match, err := m.Match(outer{Name: "actual"})
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	raw, err := Report(m.Failure())
+	assert.NoError(t, err)
+
+	var got []Mismatch
+	assert.NoError(t, json.Unmarshal(raw, &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, ".Name", got[0].Path)
+	assert.Nil(t, got[0].Actual)
+	assert.NotEmpty(t, got[0].Reason)
+}
+
+// equalMatcher is a minimal GomegaMatcher stand-in, used so this test doesn't depend on gomega's
+// own matchers implementing Bounded.
+type equalMatcher struct{ expected interface{} }
+
+func (e equalMatcher) Match(actual interface{}) (bool, error) { return actual == e.expected, nil }
+func (e equalMatcher) FailureMessage(actual interface{}) string {
+	return "mismatch"
+}
+func (e equalMatcher) NegatedFailureMessage(actual interface{}) string {
+	return "unexpected match"
+}
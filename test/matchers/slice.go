@@ -19,6 +19,7 @@ package matchers
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"k8s.io/kubernetes/pkg/util/errors"
 
@@ -26,13 +27,35 @@ import (
 	"github.com/onsi/gomega/types"
 )
 
+// SliceMatcher matches a slice element-by-element, identifying each element by Identifier. Like
+// StructMatcher, an element's matcher may itself be a *StructMatcher, *SliceMatcher, or
+// *MapMatcher, and is matched recursively with its errors nested under "[id]".
 type SliceMatcher struct {
 	// Matchers for each element.
 	Elements Elements
 	// Whether missing or extra elements are considered an error.
 	Strict bool
+	// IgnoreExtras allows elements present in the actual slice but not listed in Elements, even
+	// when Strict is set.
+	IgnoreExtras bool
+	// IgnoreMissing allows elements listed in Elements that aren't actually present, even when
+	// Strict is set.
+	IgnoreMissing bool
 	// Function for identifying a slice element.
 	Identifier Identifier
+	// Ordered additionally requires the elements identified by Order to appear in the actual
+	// slice in that relative order. Elements not listed in Order (e.g. permitted extras) may
+	// appear anywhere; Ordered has no effect on whether they're allowed at all.
+	Ordered bool
+	// Order lists the identifiers, in the order they're expected to appear, when Ordered is set.
+	Order []string
+	// positional makes matching use the actual element's index (as a string) instead of
+	// Identifier's result to look elements up in Elements, while still calling Identifier to
+	// label elements in diagnostics. Set by MatchSliceOrdered, whose elems have no natural
+	// identifier of their own to key Elements by.
+	positional bool
+
+	failure error // the mismatches from the most recent failed Match, used by FailureMessage.
 }
 
 // Element ID to matcher.
@@ -41,16 +64,41 @@ type Elements map[string]types.GomegaMatcher
 // Function for identifying elements of a slice.
 type Identifier func(element interface{}) string
 
+// MatchSliceOrdered returns a SliceMatcher requiring the actual slice to match elems one-to-one,
+// in argument order, without requiring the caller to assign each element a string ID and build
+// an Elements map by hand. id is used the same way as for StrictSlice, to label elements in
+// diagnostics (e.g. FailureMessage output); matching itself is purely positional. Useful for
+// asserting ordered container statuses or ordered PSA violation lists.
+func MatchSliceOrdered(id Identifier, elems ...types.GomegaMatcher) *SliceMatcher {
+	elements := make(Elements, len(elems))
+	order := make([]string, len(elems))
+	for i, elem := range elems {
+		key := strconv.Itoa(i)
+		elements[key] = elem
+		order[i] = key
+	}
+	return &SliceMatcher{
+		Identifier: id,
+		Elements:   elements,
+		Strict:     true,
+		Ordered:    true,
+		Order:      order,
+		positional: true,
+	}
+}
+
 func (m *SliceMatcher) Match(actual interface{}) (success bool, err error) {
 	if reflect.TypeOf(actual).Kind() != reflect.Slice {
 		return false, fmt.Errorf("%v is type %T, expected slice", actual, actual)
 	}
 
 	errs := m.matchElements(actual)
-	if len(errs) > 0 {
-		return false, errors.NewAggregate(errs)
+	if len(errs) == 0 {
+		m.failure = nil
+		return true, nil
 	}
-	return true, nil
+	m.failure = errors.NewAggregate(errs)
+	return false, nil
 }
 
 func (m *SliceMatcher) matchElements(actual interface{}) (errs []error) {
@@ -62,45 +110,88 @@ func (m *SliceMatcher) matchElements(actual interface{}) (errs []error) {
 	}()
 
 	val := reflect.ValueOf(actual)
-	elements := map[string]bool{}
+	seen := map[string]bool{}
+	var actualOrder []string
 	for i := 0; i < val.Len(); i++ {
 		element := val.Index(i).Interface()
-		id := m.Identifier(element)
-		if elements[id] {
-			errs = append(errs, fmt.Errorf("found duplicate element ID %s", id))
+		label := m.Identifier(element)
+		key := label
+		if m.positional {
+			key = strconv.Itoa(i)
+		}
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("found duplicate element ID %s", label))
 			continue
 		}
-		elements[id] = true
+		seen[key] = true
 
-		matcher, expected := m.Elements[id]
+		matcher, expected := m.Elements[key]
 		if !expected {
-			if m.Strict {
-				errs = append(errs, fmt.Errorf("unexpected element %s", id))
+			if m.Strict && !m.IgnoreExtras {
+				errs = append(errs, fmt.Errorf("unexpected element %s: %s", label, format.Object(element, 1)))
 			}
 			continue
 		}
+		if m.Ordered {
+			actualOrder = append(actualOrder, key)
+		}
 
 		match, err := matcher.Match(element)
-		if match {
+		if err != nil {
+			errs = append(errs, Nest(fmt.Sprintf("[%s]", label), err))
 			continue
 		}
+		if !match {
+			errs = append(errs, Nest(fmt.Sprintf("[%s]", label), mismatchError(matcher, element)))
+		}
+	}
 
-		errs = append(errs, Nest(fmt.Sprintf("[%s]", id), err))
+	if m.Strict && !m.IgnoreMissing {
+		for key := range m.Elements {
+			if !seen[key] {
+				errs = append(errs, fmt.Errorf("missing expected element %s", key))
+			}
+		}
 	}
 
-	if m.Strict {
-		for id := range m.Elements {
-			if !elements[id] {
-				errs = append(errs, fmt.Errorf("missing expected element %s", id))
+	if m.Ordered {
+		listed := make(map[string]bool, len(m.Order))
+		for _, key := range m.Order {
+			listed[key] = true
+		}
+		var got, want []string
+		for _, key := range actualOrder {
+			if listed[key] {
+				got = append(got, key)
+			}
+		}
+		for _, key := range m.Order {
+			if seen[key] {
+				want = append(want, key)
 			}
 		}
+		if !reflect.DeepEqual(got, want) {
+			errs = append(errs, fmt.Errorf("elements out of order: expected %v, got %v", want, got))
+		}
 	}
 
 	return errs
 }
 
+// FailureMessage renders a per-element diff: for each offending ID, whether it was missing,
+// unexpected, out of order, or which sub-matcher failed with its own FailureMessage, preceded by
+// a header summarizing how many elements mismatched.
 func (m *SliceMatcher) FailureMessage(actual interface{}) (message string) {
-	return format.Message(actual, "to match slice matcher")
+	if m.failure == nil {
+		return format.Message(actual, "to match slice matcher")
+	}
+	diff := Diff(m.failure)
+	count := 1
+	if ag, ok := m.failure.(errors.Aggregate); ok {
+		count = len(ag.Errors())
+	}
+	return fmt.Sprintf("Expected%s\nto match slice matcher, but found %d mismatch(es):\n%s",
+		format.Object(actual, 1), count, diff)
 }
 
 func (m *SliceMatcher) NegatedFailureMessage(actual interface{}) (message string) {
@@ -84,3 +84,40 @@ func TestSliceMatcher(t *testing.T) {
 func id(element interface{}) string {
 	return element.(string)
 }
+
+func TestSliceMatcherOrdered(t *testing.T) {
+	inOrder := []string{"a", "b", "c"}
+	outOfOrder := []string{"b", "a", "c"}
+
+	handBuilt := &SliceMatcher{
+		Identifier: id,
+		Elements: Elements{
+			"a": gomega.Equal("a"),
+			"b": gomega.Equal("b"),
+			"c": gomega.Equal("c"),
+		},
+		Strict:  true,
+		Ordered: true,
+		Order:   []string{"a", "b", "c"},
+	}
+	ordered := MatchSliceOrdered(id, gomega.Equal("a"), gomega.Equal("b"), gomega.Equal("c"))
+
+	tests := []struct {
+		actual      interface{}
+		matcher     types.GomegaMatcher
+		expectMatch bool
+		msg         string
+	}{
+		{inOrder, handBuilt, true, "hand-built Ordered SliceMatcher should match elements in order"},
+		{outOfOrder, handBuilt, false, "hand-built Ordered SliceMatcher should fail elements out of order"},
+		{inOrder, ordered, true, "MatchSliceOrdered should match elements in order"},
+		{outOfOrder, ordered, false, "MatchSliceOrdered should fail elements out of order"},
+	}
+
+	for i, test := range tests {
+		match, err := test.matcher.Match(test.actual)
+		assert.NoError(t, err, "[%d] %s", i, test.msg)
+		assert.Equal(t, test.expectMatch, match,
+			"[%d] %s: %s", i, test.msg, test.matcher.FailureMessage(test.actual))
+	}
+}
@@ -26,11 +26,22 @@ import (
 	"github.com/onsi/gomega/types"
 )
 
+// StructMatcher matches a struct field-by-field. A field's matcher may itself be a
+// *StructMatcher, *SliceMatcher, or *MapMatcher, in which case it is matched recursively; the
+// resulting errors are nested under the enclosing field's path (e.g. ".Spec.Containers[0].Image").
 type StructMatcher struct {
 	// Matchers for each field.
 	Fields Fields
 	// Whether missing or extra fields are considered an error.
 	Strict bool
+	// IgnoreExtras allows fields present on the actual struct but not listed in Fields, even when
+	// Strict is set.
+	IgnoreExtras bool
+	// IgnoreMissing allows fields listed in Fields that aren't actually present, even when Strict
+	// is set.
+	IgnoreMissing bool
+
+	failure error // the mismatches from the most recent failed Match, used by FailureMessage.
 }
 
 // Field name to matcher.
@@ -42,10 +53,12 @@ func (m *StructMatcher) Match(actual interface{}) (success bool, err error) {
 	}
 
 	errs := m.matchFields(actual)
-	if len(errs) > 0 {
-		return false, errors.NewAggregate(errs)
+	if len(errs) == 0 {
+		m.failure = nil
+		return true, nil
 	}
-	return true, nil
+	m.failure = errors.NewAggregate(errs)
+	return false, nil
 }
 
 func (m *StructMatcher) matchFields(actual interface{}) (errs []error) {
@@ -54,6 +67,11 @@ func (m *StructMatcher) matchFields(actual interface{}) (errs []error) {
 	fields := map[string]bool{}
 	for i := 0; i < val.NumField(); i++ {
 		fieldName := typ.Field(i).Name
+		if typ.Field(i).PkgPath != "" {
+			// Unexported field: reflect can't read its value, so it can never be listed in
+			// Fields. Skip it rather than panicking or counting it as unexpected.
+			continue
+		}
 		fields[fieldName] = true
 
 		err := func() (err error) {
@@ -67,21 +85,28 @@ func (m *StructMatcher) matchFields(actual interface{}) (errs []error) {
 
 			matcher, expected := m.Fields[fieldName]
 			if !expected {
-				if m.Strict {
-					return fmt.Errorf("unexpected field %s: %+v", fieldName, actual) // FIXME
+				if m.Strict && !m.IgnoreExtras {
+					return fmt.Errorf("unexpected field: %s", format.Object(val.Field(i).Interface(), 1))
 				}
 				return nil
 			}
 
-			_, err = matcher.Match(val.Field(i).Interface())
-			return err
+			fieldVal := val.Field(i).Interface()
+			match, err := matcher.Match(fieldVal)
+			if err != nil {
+				return err
+			}
+			if !match {
+				return mismatchError(matcher, fieldVal)
+			}
+			return nil
 		}()
 		if err != nil {
 			errs = append(errs, Nest("."+fieldName, err))
 		}
 	}
 
-	if m.Strict {
+	if m.Strict && !m.IgnoreMissing {
 		for field := range m.Fields {
 			if !fields[field] {
 				errs = append(errs, fmt.Errorf("missing expected field %s", field))
@@ -92,8 +117,19 @@ func (m *StructMatcher) matchFields(actual interface{}) (errs []error) {
 	return errs
 }
 
+// Failure returns the aggregate error from the most recent failed Match, or nil if the last
+// Match succeeded (or none has run yet). Feed it to Report for a machine-readable rendering of
+// the same mismatches FailureMessage describes as text.
+func (m *StructMatcher) Failure() error {
+	return m.failure
+}
+
 func (m *StructMatcher) FailureMessage(actual interface{}) (message string) {
-	return format.Message(actual, "to match struct matcher")
+	if m.failure == nil {
+		return format.Message(actual, "to match struct matcher")
+	}
+	return fmt.Sprintf("Expected%s\nto match struct matcher, but found:\n%s",
+		format.Object(actual, 1), Diff(m.failure))
 }
 
 func (m *StructMatcher) NegatedFailureMessage(actual interface{}) (message string) {
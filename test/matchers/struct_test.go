@@ -86,3 +86,34 @@ func TestStructMatcher(t *testing.T) {
 		}
 	}
 }
+
+func TestStructMatcherRecursion(t *testing.T) {
+	type Inner struct{ Name string }
+	type Embedded struct{ Name string }
+	type outer struct {
+		Embedded
+		Inner      Inner
+		unexported string
+	}
+
+	nested := StrictStruct(Fields{
+		"Embedded": StrictStruct(Fields{"Name": gomega.Equal("outer")}),
+		"Inner":    StrictStruct(Fields{"Name": gomega.Equal("inner")}),
+	})
+	nestedFail := StrictStruct(Fields{
+		"Embedded": StrictStruct(Fields{"Name": gomega.Equal("wrong")}),
+		"Inner":    StrictStruct(Fields{"Name": gomega.Equal("inner")}),
+	})
+
+	actual := outer{Embedded: Embedded{Name: "outer"}, Inner: Inner{Name: "inner"}, unexported: "hidden"}
+
+	match, err := nested.Match(actual)
+	assert.NoError(t, err, "recursive match should not error")
+	assert.True(t, match, "embedded and nested struct fields should match recursively: %s", nested.FailureMessage(actual))
+
+	match, err = nestedFail.Match(actual)
+	assert.NoError(t, err, "recursive mismatch should not error")
+	assert.False(t, match, "mismatched nested field should fail")
+	assert.Contains(t, nestedFail.FailureMessage(actual), ".Embedded.Name",
+		"failure message should nest the mismatch under the embedded field's path")
+}